@@ -2,8 +2,10 @@ package main
 
 import (
 	"compress/gzip"
+	"fmt"
 	"github.com/alrtve/binary"
 	"io/ioutil"
+	"math/rand"
 	"os"
 	"spell"
 	"spell/scorer"
@@ -48,29 +50,6 @@ func (cf CacheFile) Exist() bool {
 }
 
 
-func getLearningTerm(term, misspelledTerm string, model *spell.Model) (learningTerm *spell.LearningTerm) {
-	term = strings.ToLower(term)
-	if !model.HasTerm(term) {
-		return
-	}
-	misspelledTerm = strings.ToLower(misspelledTerm)
-	rawSuggestions := model.GetRawSuggestions(misspelledTerm, true)
-	suggestions := make([]spell.Suggestion, 0)
-	for _, suggestion := range rawSuggestions {
-		if misspelledTerm != suggestion.Term && suggestion.Prescription != nil {
-			suggestions = append(suggestions, suggestion)
-		}
-	}
-	if len(suggestions) > 0 {
-		learningTerm =  &spell.LearningTerm{
-			Term: term,
-			Misspell: misspelledTerm,
-			Suggestions: suggestions,
-		}
-	}
-	return
-}
-
 func GetModelFromCache(modelFileName, trainTextFileName string) (model *spell.Model, err error) {
 	modelCacheFile, err := OpenCacheFile(modelFileName)
 	if err != nil {
@@ -81,8 +60,14 @@ func GetModelFromCache(modelFileName, trainTextFileName string) (model *spell.Mo
 	if modelCacheFile.Exist() {
 		r := spell.Model{}
 		r.InitMeasurers()
-		err := binary.UnmarshalFrom(modelCacheFile, &r)
-		return &r, err
+		if err = binary.UnmarshalFrom(modelCacheFile, &r); err != nil {
+			return
+		}
+		if r.FormatVersion != spell.ModelFormatVersion {
+			err = fmt.Errorf("cached model %s has format version %d, %d is supported, remove it to retrain", modelFileName, r.FormatVersion, spell.ModelFormatVersion)
+			return
+		}
+		return &r, nil
 
 	}
 	fp, err := os.Open(trainTextFileName)
@@ -117,7 +102,13 @@ func GetLearningDataFromCache(model *spell.Model, learningDataFileName, misspell
 	defer learningDataFile.Close()
 	if learningDataFile.Exist() {
 		learningData = []*spell.LearningTerm{}
-		binary.UnmarshalFrom(learningDataFile, &learningData)
+		if err = binary.UnmarshalFrom(learningDataFile, &learningData); err != nil {
+			return
+		}
+		// frequencies are set from the model, caches written before they were added hold zeros
+		for _, learningTerm := range learningData {
+			model.SetFrequencies(learningTerm.Misspell, learningTerm.Suggestions)
+		}
 		return
 	}
 
@@ -153,7 +144,7 @@ func GetLearningDataFromCache(model *spell.Model, learningDataFileName, misspell
 
 	for _, misspell := range misspells {
 		for _, misspelledTerm := range misspell.Misspells {
-			learningTerm := getLearningTerm(misspell.Term, misspelledTerm, model)
+			learningTerm := model.GetLearningTerm(misspell.Term, misspelledTerm)
 			if learningTerm != nil {
 				learningData = append(learningData, learningTerm)
 			}
@@ -166,19 +157,27 @@ func GetLearningDataFromCache(model *spell.Model, learningDataFileName, misspell
 	return
 }
 
-func GetScorerFromCache(learningModelFileName string, learningData []*spell.LearningTerm) (learninigModel spell.ScoreModel, err error) {
+func GetScorerFromCache(learningModelFileName string, learningData []*spell.LearningTerm, vectoriser *scorer.Vectoriser, seed int64, source *rand.Rand, onEvent spell.LearnEventHandler) (learninigModel *linear.Scorer, err error) {
 	learningDataFile, err := OpenCacheFile(learningModelFileName)
 	if err != nil {
 		return
 	}
 	if learningDataFile.Exist() {
-		learninigModel = &linear.Scorer{}
-		err = binary.UnmarshalFrom(learningDataFile, learninigModel)
+		linearScorer := &linear.Scorer{}
+		if err = binary.UnmarshalFrom(learningDataFile, linearScorer); err != nil {
+			return
+		}
+		linearScorer.Vectoriser = vectoriser
+		if err = linearScorer.Validate(); err != nil {
+			err = fmt.Errorf("cached scorer %s doesn't fit the features, remove it to retrain: %v", learningModelFileName, err)
+			return
+		}
+		learninigModel = linearScorer
 		return
 	}
 
-	learnAlgorithm := &linear.Learner{Vectoriser: scorer.InitVectoriser()}
-	learninigModel = learnAlgorithm.Learn(learningData)
+	learnAlgorithm := &linear.Learner{Vectoriser: vectoriser, Seed: seed, Source: source, OnEvent: onEvent}
+	learninigModel = learnAlgorithm.Learn(learningData).(*linear.Scorer)
 	err = binary.MarshalTo(learninigModel, learningDataFile)
 	if err == nil {
 		learningDataFile.Commit()
@@ -186,3 +185,28 @@ func GetScorerFromCache(learningModelFileName string, learningData []*spell.Lear
 	return
 
 }
+
+func LoadScorerJSON(fileName string, vectoriser *scorer.Vectoriser) (*linear.Scorer, error) {
+	file, err := os.Open(fileName)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+	learningModel, err := linear.ReadScorerJSON(file, vectoriser)
+	if err != nil {
+		return nil, fmt.Errorf("can't load scorer from %s: %v", fileName, err)
+	}
+	return learningModel, nil
+}
+
+func ExportScorerJSON(fileName string, learningModel *linear.Scorer) error {
+	file, err := os.Create(fileName)
+	if err != nil {
+		return err
+	}
+	if err = learningModel.WriteJSON(file); err != nil {
+		file.Close()
+		return err
+	}
+	return file.Close()
+}
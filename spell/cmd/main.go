@@ -1,19 +1,31 @@
 package main
 
 import (
+	"flag"
 	"fmt"
 	"github.com/alrtve/binary"
 	"log"
 	"math/rand"
+	"os"
 	"path"
 	"reflect"
+	"spell"
+	"spell/evaluation"
+	"spell/keyboard"
+	"spell/scorer"
 	"spell/scorer/linear"
 	"spell/scorer/probabilistic"
-	"time"
 )
 
 func main() {
-	rand.Seed(time.Now().UnixNano())
+	seed := flag.Int64("seed", 1, "seed of the learning, the same seed and data give the same scorer")
+	eventsFileName := flag.String("events", "", "file to write learning events to as JSON lines")
+	reportFileName := flag.String("report", "", "file to write the evaluation report to as JSON")
+	folds := flag.Int("folds", 0, "cross-validate the learner with that many folds instead of training on the whole data")
+	explain := flag.Bool("explain", false, "compare feature contributions of the expected term and the winner for every miss")
+	loadFileName := flag.String("load", "", "JSON file to load the scorer from instead of training it")
+	exportFileName := flag.String("export", "", "JSON file to export the scorer to")
+	flag.Parse()
 
 	binary.RegisterType(reflect.TypeOf((*linear.Scorer)(nil)).Elem())
 	binary.RegisterType(reflect.TypeOf((*probabilistic.Scorer)(nil)).Elem())
@@ -36,33 +48,88 @@ func main() {
 		log.Fatal(err)
 	}
 
-	scorer, err := GetScorerFromCache(learningModelFileName, learningData)
+	vectoriser, err := scorer.InitVectoriserWithFeatures(keyboard.Qwerty, scorer.ExtendedFeatures)
 	if err != nil {
 		log.Fatal(err)
 	}
+	// every random consumer of the run takes its numbers from here, so the seed alone defines the run
+	source := rand.New(rand.NewSource(*seed))
+	if *folds > 1 {
+		splitter := evaluation.InitSplitter(source)
+		splitter.Stratified = true
+		splits, err := splitter.KFold(learningData, *folds)
+		if err != nil {
+			log.Fatal(err)
+		}
+		validator := evaluation.InitCrossValidator(evaluation.InitEvaluator(model))
+		learner := &linear.Learner{Vectoriser: vectoriser, Seed: *seed, Source: source}
+		if err = validator.Run(learner, splits).WriteTable(os.Stdout); err != nil {
+			log.Fatal(err)
+		}
+		return
+	}
 
-	totalCount := 0
-	validCount := 0
-	for _, learningTerm := range learningData {
-		suggestions := model.GetSuggestions(learningTerm.Misspell, scorer, true)
-		if model.HasTerm(learningTerm.Term) && len(suggestions) > 0 {
-			if suggestions[0].Term != learningTerm.Term && suggestions[0].Prescription != nil {
-				fmt.Println(learningTerm.Misspell)
-				fmt.Println(learningTerm.Term)
-				for i := 0 ; i < 3 && i < len(suggestions); i++ {
-					if suggestions[i].Prescription != nil {
-						suggestions[i].Prescription.Dump()
-					} else {
-						fmt.Println(suggestions[i].Term)
-					}
-				}
-				fmt.Printf("\n\n")
-				totalCount++
+	var onEvent spell.LearnEventHandler
+	if *eventsFileName != "" {
+		eventsFile, err := os.Create(*eventsFileName)
+		if err != nil {
+			log.Fatal(err)
+		}
+		defer eventsFile.Close()
+		onEvent = spell.NewLearnEventLogger(eventsFile).Log
+	}
+
+	var linearScorer *linear.Scorer
+	if *loadFileName != "" {
+		linearScorer, err = LoadScorerJSON(*loadFileName, vectoriser)
+	} else {
+		linearScorer, err = GetScorerFromCache(learningModelFileName, learningData, vectoriser, *seed, source, onEvent)
+	}
+	if err != nil {
+		log.Fatal(err)
+	}
+	if *exportFileName != "" {
+		if err = ExportScorerJSON(*exportFileName, linearScorer); err != nil {
+			log.Fatal(err)
+		}
+	}
+
+	evaluator := evaluation.InitEvaluator(model)
+	evaluator.OnMiss = func(learningTerm *spell.LearningTerm, suggestions []spell.Suggestion) {
+		if len(suggestions) == 0 || suggestions[0].Prescription == nil {
+			return
+		}
+		fmt.Println(learningTerm.Misspell)
+		fmt.Println(learningTerm.Term)
+		for i := 0 ; i < 3 && i < len(suggestions); i++ {
+			if suggestions[i].Prescription != nil {
+				suggestions[i].Prescription.Dump(os.Stdout)
 			} else {
-				validCount++
+				fmt.Println(suggestions[i].Term)
+			}
+		}
+		if *explain {
+			for i := range suggestions {
+				if suggestions[i].Term == learningTerm.Term {
+					evaluation.WriteComparison(os.Stdout, linearScorer, &suggestions[i], &suggestions[0])
+					break
+				}
 			}
 		}
+		fmt.Printf("\n\n")
+	}
+	report := evaluator.Evaluate(linearScorer, learningData)
+	if err = report.WriteTable(os.Stdout); err != nil {
+		log.Fatal(err)
+	}
+	if *reportFileName != "" {
+		reportFile, err := os.Create(*reportFileName)
+		if err != nil {
+			log.Fatal(err)
+		}
+		defer reportFile.Close()
+		if err = report.WriteJSON(reportFile); err != nil {
+			log.Fatal(err)
+		}
 	}
-	fmt.Println(totalCount)
-	fmt.Println(validCount)
 }
\ No newline at end of file
@@ -0,0 +1,84 @@
+package spell
+
+/**
+	CostModel gives weights of edit actions for DistanceMeasurer.WeightedDistance.
+	Insertions and deletions are weighted in the context of the preceding term char (TermStart at the term start),
+	compound actions (Transposition, Duplicate, MissDouble, Triplet) have per-action costs
+ */
+type CostModel interface {
+	InsertCost(prev, inserted rune) float64
+	DeleteCost(prev, deleted rune) float64
+	ReplaceCost(from, to rune) float64
+	ActionCost(action EditAction) float64
+}
+
+/**
+	AnyChar matches every char in TableCostModel keys
+ */
+const AnyChar = rune(0)
+
+/**
+	TermStart is the preceding char of insertions and deletions at the term start,
+	so their costs are configured apart from the AnyChar wildcard
+ */
+const TermStart = '^'
+
+type CharPair struct {
+	A rune
+	B rune
+}
+
+/**
+	TableCostModel looks costs up in tables, falling back from exact pairs to AnyChar pairs,
+	then to ActionCosts and finally to 1 (0 for Match)
+ */
+type TableCostModel struct {
+	ActionCosts   map[EditAction]float64
+	Substitutions map[CharPair]float64
+	Insertions    map[CharPair]float64
+	Deletions     map[CharPair]float64
+}
+
+func NewTableCostModel() *TableCostModel {
+	return &TableCostModel{
+		ActionCosts:   map[EditAction]float64{},
+		Substitutions: map[CharPair]float64{},
+		Insertions:    map[CharPair]float64{},
+		Deletions:     map[CharPair]float64{},
+	}
+}
+
+func (costModel *TableCostModel) InsertCost(prev, inserted rune) float64 {
+	return costModel.pairCost(costModel.Insertions, Insert, prev, inserted)
+}
+
+func (costModel *TableCostModel) DeleteCost(prev, deleted rune) float64 {
+	return costModel.pairCost(costModel.Deletions, Delete, prev, deleted)
+}
+
+func (costModel *TableCostModel) ReplaceCost(from, to rune) float64 {
+	return costModel.pairCost(costModel.Substitutions, Replace, from, to)
+}
+
+func (costModel *TableCostModel) ActionCost(action EditAction) float64 {
+	if cost, ok := costModel.ActionCosts[action]; ok {
+		return cost
+	}
+	if action == Match {
+		return 0
+	}
+	return 1
+}
+
+func (costModel *TableCostModel) pairCost(table map[CharPair]float64, action EditAction, a, b rune) float64 {
+	if cost, ok := table[CharPair{a, b}]; ok {
+		return cost
+	}
+	if cost, ok := table[CharPair{AnyChar, b}]; ok {
+		return cost
+	}
+	if cost, ok := table[CharPair{a, AnyChar}]; ok {
+		return cost
+	}
+	return costModel.ActionCost(action)
+}
@@ -29,4 +29,7 @@ const (
 	DefaultIndexSplitLen  = 5
 	DefaultMinTermLen = 4
 	DefaultMinTermCount = 10
+
+	// layout fixes are ranked right after the corrections within that many edits
+	LayoutFixDistance = 1
 )
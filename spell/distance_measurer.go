@@ -3,14 +3,22 @@ package spell
 type DistanceMeasurer struct {
 	p          [][] int
 	e          [][] EditAction
+	w          [][] float64
 	maxRows    int
 	maxColumns int
+	costModel  CostModel
 }
 
 func NewDistanceMeasurer() *DistanceMeasurer {
 	return &DistanceMeasurer{}
 }
 
+func NewWeightedDistanceMeasurer(costModel CostModel) *DistanceMeasurer {
+	return &DistanceMeasurer{
+		costModel: costModel,
+	}
+}
+
 func (measurer *DistanceMeasurer) Distance(a, b string, calcEditorialPrescription bool) (distance int, editorialPrescription *EditorialPrescription) {
 	var (
 		ar = []rune(a)
@@ -19,97 +27,335 @@ func (measurer *DistanceMeasurer) Distance(a, b string, calcEditorialPrescriptio
 		lb = len(br) + 1
 	)
 	measurer.ensureSizes(la, lb)
-	for i := 0; i < measurer.maxRows; i++ {
-		measurer.p[i][0] = i
-		measurer.e[i][0] = Insert
+	measurer.initBorders()
+
+	maxDistance := la + lb
+	for i := 1; i < lb; i++ {
+		for j := 1; j < la; j++ {
+			measurer.p[i][j], measurer.e[i][j] = measurer.cell(ar, br, i, j, maxDistance)
+		}
 	}
-	for j := 0; j < measurer.maxColumns; j++ {
-		measurer.p[0][j] = j
-		measurer.e[0][j] = Delete
+	distance = measurer.p[lb-1][la-1]
+	if calcEditorialPrescription {
+		editorialPrescription = measurer.getEditorialPrescription(ar, br, la, lb)
 	}
+	return
+}
 
-	maxDistance := la + lb
-	if lb > maxDistance {
-		maxDistance = la
+/**
+	Same as Distance but gives up as soon as the distance is known to be greater than maxDistance.
+	Every operation shifts the matrix diagonal by no more than its cost, so only the band |i - j| <= maxDistance
+	is computed. Any path to the last cell passes one of three successive rows (triplets skip two rows at most),
+	so computation stops once the band minimum of the last three rows exceeds maxDistance.
+	In that case maxDistance + 1 is returned without a prescription
+ */
+func (measurer *DistanceMeasurer) BoundedDistance(a, b string, maxDistance int, calcEditorialPrescription bool) (distance int, editorialPrescription *EditorialPrescription) {
+	var (
+		ar       = []rune(a)
+		br       = []rune(b)
+		la       = len(ar) + 1
+		lb       = len(br) + 1
+		exceeded = maxDistance + 1
+	)
+	if la-lb > maxDistance || lb-la > maxDistance {
+		return exceeded, nil
 	}
+	measurer.ensureSizes(la, lb)
+	measurer.initBorders()
+
+	var (
+		infinity = la + lb
+		rowsMins = [3]int{0, 0, 0}
+	)
 	for i := 1; i < lb; i++ {
-		for j := 1; j < la; j++ {
-			del := measurer.p[i][j-1] + 1
-			ins := measurer.p[i-1][j] + 1
-			repl := measurer.p[i-1][j-1] + 1
-			match := maxDistance
-			if br[i-1] == ar[j-1] {
-				match = measurer.p[i-1][j-1]
-			}
+		jFrom := i - maxDistance
+		if jFrom < 1 {
+			jFrom = 1
+		}
+		jTo := i + maxDistance
+		if jTo > la-1 {
+			jTo = la - 1
+		}
+		// cells right outside the band are read by the band edges
+		if jFrom > 1 {
+			measurer.p[i][jFrom-1] = infinity
+		}
+		if jTo < la-1 {
+			measurer.p[i][jTo+1] = infinity
+		}
 
-			transpose := maxDistance
-			if i >= 2 && j >= 2 {
-				if br[i-1] == ar[j-2] && br[i-2] == ar[j-1] {
-					transpose = measurer.p[i-2][j-2] + 1
-				}
+		rowMin := infinity
+		if jFrom == 1 {
+			rowMin = measurer.p[i][0]
+		}
+		for j := jFrom; j <= jTo; j++ {
+			measurer.p[i][j], measurer.e[i][j] = measurer.cell(ar, br, i, j, infinity)
+			if measurer.p[i][j] < rowMin {
+				rowMin = measurer.p[i][j]
 			}
+		}
+		rowsMins[i%3] = rowMin
+		if rowsMins[0] > maxDistance && rowsMins[1] > maxDistance && rowsMins[2] > maxDistance {
+			return exceeded, nil
+		}
+	}
 
-			doupl := maxDistance
-			if i >= 2 && ar[j-1] == br[i-1] && ar[j-1] == br[i-2] {
-				doupl = measurer.p[i-1][j] + 1
-			}
-			missDoupl := maxDistance
-			if j >= 2 && ar[j-1] == br[i-1] && ar[j-2] == br[i-1] {
-				missDoupl = measurer.p[i][j-1] + 1
-			}
+	distance = measurer.p[lb-1][la-1]
+	if distance > maxDistance {
+		return exceeded, nil
+	}
+	if calcEditorialPrescription {
+		editorialPrescription = measurer.getEditorialPrescription(ar, br, la, lb)
+	}
+	return
+}
 
-			triplet := maxDistance
-			// for triplets chars must be pairwise different
-			if i >= 3 && j >= 3 && a[j-3] != a[j-2] && a[j-3] != a[j-1] && a[j-2] != a[j-1] {
-				if ar[j-3] == br[i-1] && ar[j-2] == br[i-3] && ar[j-1] == br[i-2] {
-					triplet = measurer.p[i-3][j-3] + 1
-				} else if ar[j-3] == br[i-2] && ar[j-2] == br[i-1] && ar[j-1] == br[i-3] {
-					triplet = measurer.p[i-3][j-3] + 1
-				}
-			}
+/**
+	Calculate distance where actions are weighted by the measurer cost model (unit costs if there is none).
+	The path is chosen by weighted distance; distance is the number of non-Match actions along that path
+ */
+func (measurer *DistanceMeasurer) WeightedDistance(a, b string, calcEditorialPrescription bool) (distance int, weightedDistance float64, editorialPrescription *EditorialPrescription) {
+	var (
+		ar        = []rune(a)
+		br        = []rune(b)
+		la        = len(ar) + 1
+		lb        = len(br) + 1
+		costModel = measurer.costModel
+	)
+	if costModel == nil {
+		costModel = NewTableCostModel()
+	}
+	measurer.ensureSizes(la, lb)
+	measurer.ensureWeightSizes(la, lb)
+	measurer.initBorders()
+
+	measurer.w[0][0] = 0
+	for i := 1; i < lb; i++ {
+		measurer.w[i][0] = measurer.w[i-1][0] + costModel.InsertCost(TermStart, br[i-1])
+	}
+	for j := 1; j < la; j++ {
+		prev := TermStart
+		if j >= 2 {
+			prev = ar[j-2]
+		}
+		measurer.w[0][j] = measurer.w[0][j-1] + costModel.DeleteCost(prev, ar[j-1])
+	}
 
-			min := del
+	for i := 1; i < lb; i++ {
+		for j := 1; j < la; j++ {
+			measurer.weightedCell(ar, br, i, j, costModel)
+		}
+	}
+	distance = measurer.p[lb-1][la-1]
+	weightedDistance = measurer.w[lb-1][la-1]
+	if calcEditorialPrescription {
+		editorialPrescription = measurer.getEditorialPrescription(ar, br, la, lb)
+	}
+	return
+}
+
+/**
+	Calculate unrestricted Damerau-Levenshtein distance (Lowrance-Wagner): chars may be transposed
+	with other chars deleted or inserted between them. Measurer specific actions (Duplicate, MissDouble, Triplet)
+	aren't used. In the prescription a transposition of chars that aren't adjacent is written as
+	Transposition, Delete..., Insert..., Transposition
+ */
+func (measurer *DistanceMeasurer) DamerauLevenshteinDistance(a, b string, calcEditorialPrescription bool) (distance int, editorialPrescription *EditorialPrescription) {
+	var (
+		ar = []rune(a)
+		br = []rune(b)
+		la = len(ar) + 1
+		lb = len(br) + 1
+		// last row where the input char was met
+		lastRows = make(map[rune]int)
+	)
+	measurer.ensureSizes(la, lb)
+	measurer.initBorders()
+
+	maxDistance := la + lb
+	for i := 1; i < lb; i++ {
+		// last column of the row where the term char matched the input char
+		lastColumn := 0
+		for j := 1; j < la; j++ {
+			k := lastRows[ar[j-1]]
+			l := lastColumn
+
+			min := measurer.p[i][j-1] + 1
 			action := Delete
-			if ins < min {
+			if ins := measurer.p[i-1][j] + 1; ins < min {
 				min = ins
 				action = Insert
 			}
-			if match <= min {
-				min = match
-				action = Match
-			}
-			if repl < min {
+			if br[i-1] == ar[j-1] {
+				lastColumn = j
+				if match := measurer.p[i-1][j-1]; match <= min {
+					min = match
+					action = Match
+				}
+			} else if repl := measurer.p[i-1][j-1] + 1; repl < min {
 				min = repl
 				action = Replace
 			}
-			if doupl <= min {
-				min = doupl
-				action = Duplicate
-			}
-			if missDoupl <= min {
-				min = missDoupl
-				action = MissDouble
+			transpose := maxDistance
+			if k > 0 && l > 0 {
+				transpose = measurer.p[k-1][l-1] + (i - k - 1) + 1 + (j - l - 1)
 			}
 			if transpose <= min {
 				min = transpose
 				action = Transposition
 			}
-			// min < del -- special case when no actions are applicable
-			if triplet <= min {
-				min = triplet
-				action = Triplet
-			}
 			measurer.p[i][j] = min
 			measurer.e[i][j] = action
 		}
+		lastRows[br[i-1]] = i
 	}
 	distance = measurer.p[lb-1][la-1]
 	if calcEditorialPrescription {
-		editorialPrescription = measurer.getEditorialPrescription(ar, br, la, lb)
+		editorialPrescription = measurer.getDamerauLevenshteinPrescription(ar, br, la, lb)
 	}
 	return
 }
 
+func (measurer *DistanceMeasurer) initBorders() {
+	for i := 0; i < measurer.maxRows; i++ {
+		measurer.p[i][0] = i
+		measurer.e[i][0] = Insert
+	}
+	for j := 0; j < measurer.maxColumns; j++ {
+		measurer.p[0][j] = j
+		measurer.e[0][j] = Delete
+	}
+}
+
+/**
+	Calculate distance and the best action for the cell (i, j); maxDistance is used for inapplicable actions
+ */
+func (measurer *DistanceMeasurer) cell(ar, br []rune, i, j, maxDistance int) (int, EditAction) {
+	del := measurer.p[i][j-1] + 1
+	ins := measurer.p[i-1][j] + 1
+	repl := measurer.p[i-1][j-1] + 1
+	match := maxDistance
+	if br[i-1] == ar[j-1] {
+		match = measurer.p[i-1][j-1]
+	}
+
+	transpose := maxDistance
+	if i >= 2 && j >= 2 {
+		if br[i-1] == ar[j-2] && br[i-2] == ar[j-1] {
+			transpose = measurer.p[i-2][j-2] + 1
+		}
+	}
+
+	doupl := maxDistance
+	if i >= 2 && ar[j-1] == br[i-1] && ar[j-1] == br[i-2] {
+		doupl = measurer.p[i-1][j] + 1
+	}
+	missDoupl := maxDistance
+	if j >= 2 && ar[j-1] == br[i-1] && ar[j-2] == br[i-1] {
+		missDoupl = measurer.p[i][j-1] + 1
+	}
+
+	triplet := maxDistance
+	// for triplets chars must be pairwise different
+	if i >= 3 && j >= 3 && ar[j-3] != ar[j-2] && ar[j-3] != ar[j-1] && ar[j-2] != ar[j-1] {
+		if ar[j-3] == br[i-1] && ar[j-2] == br[i-3] && ar[j-1] == br[i-2] {
+			triplet = measurer.p[i-3][j-3] + 1
+		} else if ar[j-3] == br[i-2] && ar[j-2] == br[i-1] && ar[j-1] == br[i-3] {
+			triplet = measurer.p[i-3][j-3] + 1
+		}
+	}
+
+	min := del
+	action := Delete
+	if ins < min {
+		min = ins
+		action = Insert
+	}
+	if match <= min {
+		min = match
+		action = Match
+	}
+	if repl < min {
+		min = repl
+		action = Replace
+	}
+	if doupl <= min {
+		min = doupl
+		action = Duplicate
+	}
+	if missDoupl <= min {
+		min = missDoupl
+		action = MissDouble
+	}
+	if transpose <= min {
+		min = transpose
+		action = Transposition
+	}
+	// min < del -- special case when no actions are applicable
+	if triplet <= min {
+		min = triplet
+		action = Triplet
+	}
+	return min, action
+}
+
+/**
+	Same as cell but actions are weighted by costModel, priorities of equally weighted actions are kept
+ */
+func (measurer *DistanceMeasurer) weightedCell(ar, br []rune, i, j int, costModel CostModel) {
+	var (
+		w        = measurer.w
+		p        = measurer.p
+		prevFrom = TermStart
+		min      float64
+		action   EditAction
+		steps    int
+	)
+	if j >= 2 {
+		prevFrom = ar[j-2]
+	}
+
+	// delete
+	min, action, steps = w[i][j-1]+costModel.DeleteCost(prevFrom, ar[j-1]), Delete, p[i][j-1]+1
+	if ins := w[i-1][j] + costModel.InsertCost(ar[j-1], br[i-1]); ins < min {
+		min, action, steps = ins, Insert, p[i-1][j]+1
+	}
+	if br[i-1] == ar[j-1] {
+		if match := w[i-1][j-1] + costModel.ActionCost(Match); match <= min {
+			min, action, steps = match, Match, p[i-1][j-1]
+		}
+	} else if repl := w[i-1][j-1] + costModel.ReplaceCost(ar[j-1], br[i-1]); repl < min {
+		min, action, steps = repl, Replace, p[i-1][j-1]+1
+	}
+	if i >= 2 && ar[j-1] == br[i-1] && ar[j-1] == br[i-2] {
+		if doupl := w[i-1][j] + costModel.ActionCost(Duplicate); doupl <= min {
+			min, action, steps = doupl, Duplicate, p[i-1][j]+1
+		}
+	}
+	if j >= 2 && ar[j-1] == br[i-1] && ar[j-2] == br[i-1] {
+		if missDoupl := w[i][j-1] + costModel.ActionCost(MissDouble); missDoupl <= min {
+			min, action, steps = missDoupl, MissDouble, p[i][j-1]+1
+		}
+	}
+	if i >= 2 && j >= 2 && br[i-1] == ar[j-2] && br[i-2] == ar[j-1] {
+		if transpose := w[i-2][j-2] + costModel.ActionCost(Transposition); transpose <= min {
+			min, action, steps = transpose, Transposition, p[i-2][j-2]+1
+		}
+	}
+	// for triplets chars must be pairwise different
+	if i >= 3 && j >= 3 && ar[j-3] != ar[j-2] && ar[j-3] != ar[j-1] && ar[j-2] != ar[j-1] {
+		if (ar[j-3] == br[i-1] && ar[j-2] == br[i-3] && ar[j-1] == br[i-2]) ||
+			(ar[j-3] == br[i-2] && ar[j-2] == br[i-1] && ar[j-1] == br[i-3]) {
+			if triplet := w[i-3][j-3] + costModel.ActionCost(Triplet); triplet <= min {
+				min, action, steps = triplet, Triplet, p[i-3][j-3]+1
+			}
+		}
+	}
+	w[i][j] = min
+	p[i][j] = steps
+	measurer.e[i][j] = action
+}
+
 func (measurer *DistanceMeasurer) getEditorialPrescription(ar, br []rune, la, lb int) *EditorialPrescription {
 	var (
 		i  = lb - 1
@@ -184,6 +430,80 @@ func (measurer *DistanceMeasurer) getEditorialPrescription(ar, br []rune, la, lb
 	}
 }
 
+func (measurer *DistanceMeasurer) getDamerauLevenshteinPrescription(ar, br []rune, la, lb int) *EditorialPrescription {
+	var (
+		i  = lb - 1
+		j  = la - 1
+		ia = i + j
+	)
+	var (
+		actions = make([]EditAction, ia)
+		froms   = make([]rune, ia)
+		tos     = make([]rune, ia)
+	)
+	ia--
+	for i > 0 || j > 0 {
+		action := measurer.e[i][j]
+		actions[ia] = action
+		froms[ia] = rune(0)
+		tos[ia] = rune(0)
+
+		switch action {
+		case Delete:
+			froms[ia] = ar[j-1]
+			j--
+		case Insert:
+			tos[ia] = br[i-1]
+			i--
+		case Match:
+			fallthrough
+		case Replace:
+			froms[ia] = ar[j-1]
+			tos[ia] = br[i-1]
+			i--
+			j--
+		case Transposition:
+			// the same lookups as the distance calculation does
+			k := i - 1
+			for br[k-1] != ar[j-1] {
+				k--
+			}
+			l := j - 1
+			for ar[l-1] != br[i-1] {
+				l--
+			}
+			froms[ia] = ar[j-1]
+			tos[ia] = br[i-1]
+			for ii := i - 1; ii > k; ii-- {
+				ia--
+				actions[ia] = Insert
+				froms[ia] = rune(0)
+				tos[ia] = br[ii-1]
+			}
+			for jj := j - 1; jj > l; jj-- {
+				ia--
+				actions[ia] = Delete
+				froms[ia] = ar[jj-1]
+				tos[ia] = rune(0)
+			}
+			ia--
+			actions[ia] = Transposition
+			froms[ia] = ar[l-1]
+			tos[ia] = br[k-1]
+			i = k - 1
+			j = l - 1
+		}
+		ia--
+	}
+	ia++
+
+	return &EditorialPrescription{
+		Froms:   froms[ia:],
+		Tos:     tos[ia:],
+		Actions: actions[ia:],
+	}
+}
+
 func (measurer *DistanceMeasurer) ensureSizes(la, lb int) {
 	if la > measurer.maxColumns || lb > measurer.maxRows {
 		measurer.p = make([][]int, lb)
@@ -196,3 +516,12 @@ func (measurer *DistanceMeasurer) ensureSizes(la, lb int) {
 		measurer.maxColumns = la
 	}
 }
+
+func (measurer *DistanceMeasurer) ensureWeightSizes(la, lb int) {
+	if len(measurer.w) < lb || len(measurer.w[0]) < la {
+		measurer.w = make([][]float64, measurer.maxRows)
+		for i := range measurer.w {
+			measurer.w[i] = make([]float64, measurer.maxColumns)
+		}
+	}
+}
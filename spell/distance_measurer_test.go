@@ -0,0 +1,109 @@
+package spell
+
+import (
+	"math/rand"
+	"reflect"
+	"testing"
+)
+
+func TestCyrillicTriplet(t *testing.T) {
+	measurer := NewDistanceMeasurer()
+	distance, prescription := measurer.Distance("при", "рип", true)
+	if distance != 1 || !reflect.DeepEqual(prescription.Actions, []EditAction{Triplet, Triplet, Triplet}) {
+		t.Errorf("при→рип: distance %d, prescription %s, expected a triplet", distance, prescription)
+	}
+	boundedDistance, boundedPrescription := measurer.BoundedDistance("при", "рип", 2, true)
+	if boundedDistance != distance || !reflect.DeepEqual(boundedPrescription, prescription) {
+		t.Errorf("при→рип: bounded distance %d %s, expected %d %s", boundedDistance, boundedPrescription, distance, prescription)
+	}
+}
+
+func TestBoundedDistanceMatchesDistance(t *testing.T) {
+	var (
+		source   = rand.New(rand.NewSource(1))
+		alphabet = []rune("abcприт")
+		measurer = NewDistanceMeasurer()
+		bounded  = NewDistanceMeasurer()
+	)
+	randomString := func() string {
+		chars := make([]rune, 1+source.Intn(7))
+		for i := range chars {
+			chars[i] = alphabet[source.Intn(len(alphabet))]
+		}
+		return string(chars)
+	}
+	for n := 0; n < 20000; n++ {
+		var (
+			a           = randomString()
+			b           = randomString()
+			maxDistance = source.Intn(4)
+		)
+		distance, prescription := measurer.Distance(a, b, true)
+		boundedDistance, boundedPrescription := bounded.BoundedDistance(a, b, maxDistance, true)
+		if distance > maxDistance {
+			if boundedDistance <= maxDistance {
+				t.Fatalf("%s→%s: bounded distance %d within %d, distance is %d", a, b, boundedDistance, maxDistance, distance)
+			}
+			continue
+		}
+		if boundedDistance != distance || !reflect.DeepEqual(boundedPrescription, prescription) {
+			t.Fatalf("%s→%s: bounded distance %d %s, expected %d %s", a, b, boundedDistance, boundedPrescription, distance, prescription)
+		}
+	}
+}
+
+func TestTermStartCostIsApartFromAnyChar(t *testing.T) {
+	costModel := NewTableCostModel()
+	costModel.Insertions[CharPair{TermStart, 'x'}] = 0.25
+	costModel.Insertions[CharPair{AnyChar, 'x'}] = 0.75
+	measurer := NewWeightedDistanceMeasurer(costModel)
+
+	if _, weightedDistance, _ := measurer.WeightedDistance("abc", "xabc", false); weightedDistance != 0.25 {
+		t.Errorf("insertion at the term start costs %v, expected 0.25", weightedDistance)
+	}
+	if _, weightedDistance, _ := measurer.WeightedDistance("abc", "abxc", false); weightedDistance != 0.75 {
+		t.Errorf("insertion in the middle costs %v, expected 0.75", weightedDistance)
+	}
+}
+
+type benchmarkPair struct {
+	term, input string
+}
+
+/**
+	Misspells against their terms and against the next term, as a lookup meets both near and far candidates
+ */
+func benchmarkPairs(b *testing.B) []benchmarkPair {
+	misspells, err := benchmarkMisspells("cmd/data/misspells.txt.gz")
+	if err != nil {
+		b.Skip(err)
+	}
+	pairs := make([]benchmarkPair, 0)
+	for i, misspell := range misspells {
+		next := misspells[(i+1)%len(misspells)].Term
+		for _, input := range misspell.Misspells {
+			pairs = append(pairs, benchmarkPair{misspell.Term, input}, benchmarkPair{next, input})
+		}
+	}
+	return pairs
+}
+
+func BenchmarkDistance(b *testing.B) {
+	pairs := benchmarkPairs(b)
+	measurer := NewDistanceMeasurer()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		pair := pairs[i%len(pairs)]
+		measurer.Distance(pair.term, pair.input, true)
+	}
+}
+
+func BenchmarkBoundedDistance(b *testing.B) {
+	pairs := benchmarkPairs(b)
+	measurer := NewDistanceMeasurer()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		pair := pairs[i%len(pairs)]
+		measurer.BoundedDistance(pair.term, pair.input, 2, true)
+	}
+}
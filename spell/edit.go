@@ -0,0 +1,225 @@
+package spell
+
+import (
+	"fmt"
+	"strings"
+)
+
+/**
+	Edit is one operation of a prescription. Transpositions and triplets occupy several prescription steps,
+	transposed chars that aren't adjacent (unrestricted Damerau-Levenshtein) have deletions and insertions
+	between their steps, those are separate edits
+ */
+type Edit struct {
+	Action EditAction
+	Steps  []int
+	Froms  []rune
+	Tos    []rune
+}
+
+/**
+	Group prescription steps into edits ordered by their first step, matches are included
+ */
+func (prescription *EditorialPrescription) Edits() []Edit {
+	var (
+		edits             = make([]Edit, 0, len(prescription.Actions))
+		openTransposition = -1
+	)
+	for i := 0; i < len(prescription.Actions); i++ {
+		action := prescription.Actions[i]
+		switch {
+		case action == Transposition && openTransposition >= 0:
+			edit := &edits[openTransposition]
+			edit.Steps = append(edit.Steps, i)
+			edit.Froms = append(edit.Froms, prescription.Froms[i])
+			edit.Tos = append(edit.Tos, prescription.Tos[i])
+			openTransposition = -1
+		case action == Transposition:
+			openTransposition = len(edits)
+			edits = append(edits, Edit{
+				Action: action,
+				Steps:  []int{i},
+				Froms:  []rune{prescription.Froms[i]},
+				Tos:    []rune{prescription.Tos[i]},
+			})
+		case action == Triplet:
+			edit := Edit{Action: action}
+			for k := i; k < i+3 && k < len(prescription.Actions) && prescription.Actions[k] == Triplet; k++ {
+				edit.Steps = append(edit.Steps, k)
+				edit.Froms = append(edit.Froms, prescription.Froms[k])
+				edit.Tos = append(edit.Tos, prescription.Tos[k])
+			}
+			edits = append(edits, edit)
+			i += len(edit.Steps) - 1
+		default:
+			edits = append(edits, Edit{
+				Action: action,
+				Steps:  []int{i},
+				Froms:  []rune{prescription.Froms[i]},
+				Tos:    []rune{prescription.Tos[i]},
+			})
+		}
+	}
+	return edits
+}
+
+/**
+	Replay the prescription on source: source must be the term the prescription was made for,
+	the result is the input
+ */
+func (prescription *EditorialPrescription) Apply(source string) (string, error) {
+	var (
+		sourceR = []rune(source)
+		pos     = 0
+		result  strings.Builder
+	)
+	for i, from := range prescription.Froms {
+		if from != 0 {
+			if pos >= len(sourceR) {
+				return "", fmt.Errorf("prescription step %d expects %q after the end of %q", i, from, source)
+			}
+			if sourceR[pos] != from {
+				return "", fmt.Errorf("prescription step %d expects %q at position %d of %q, got %q", i, from, pos, source, sourceR[pos])
+			}
+			pos++
+		}
+		if to := prescription.Tos[i]; to != 0 {
+			result.WriteRune(to)
+		}
+	}
+	if pos != len(sourceR) {
+		return "", fmt.Errorf("prescription doesn't cover %q of %q", string(sourceR[pos:]), source)
+	}
+	return result.String(), nil
+}
+
+/**
+	Make prescription turning the input back into the term
+ */
+func (prescription *EditorialPrescription) Invert() *EditorialPrescription {
+	inverted := &EditorialPrescription{
+		Froms:   make([]rune, len(prescription.Tos)),
+		Tos:     make([]rune, len(prescription.Froms)),
+		Actions: make([]EditAction, len(prescription.Actions)),
+	}
+	copy(inverted.Froms, prescription.Tos)
+	copy(inverted.Tos, prescription.Froms)
+	for i, action := range prescription.Actions {
+		switch action {
+		case Insert:
+			action = Delete
+		case Delete:
+			action = Insert
+		case Duplicate:
+			action = MissDouble
+		case MissDouble:
+			action = Duplicate
+		}
+		inverted.Actions[i] = action
+	}
+	return inverted
+}
+
+/**
+	Check that the prescription turns from into to and every edit is consistent with its action
+ */
+func (prescription *EditorialPrescription) Validate(from, to string) error {
+	if len(prescription.Froms) != len(prescription.Actions) || len(prescription.Tos) != len(prescription.Actions) {
+		return fmt.Errorf("prescription has %d actions, %d froms and %d tos", len(prescription.Actions), len(prescription.Froms), len(prescription.Tos))
+	}
+	if froms := joinChars(prescription.Froms); froms != from {
+		return fmt.Errorf("prescription turns %q, not %q", froms, from)
+	}
+	if tos := joinChars(prescription.Tos); tos != to {
+		return fmt.Errorf("prescription makes %q, not %q", tos, to)
+	}
+
+	for _, edit := range prescription.Edits() {
+		if err := prescription.validateEdit(edit); err != nil {
+			return fmt.Errorf("prescription step %d: %v", edit.Steps[0], err)
+		}
+	}
+	return nil
+}
+
+func (prescription *EditorialPrescription) validateEdit(edit Edit) error {
+	var (
+		from = edit.Froms[0]
+		to   = edit.Tos[0]
+	)
+	switch edit.Action {
+	case Match:
+		if from == 0 || from != to {
+			return fmt.Errorf("match of %q and %q", from, to)
+		}
+	case Replace:
+		if from == 0 || to == 0 || from == to {
+			return fmt.Errorf("replace of %q by %q", from, to)
+		}
+	case Insert:
+		if from != 0 || to == 0 {
+			return fmt.Errorf("insert must have only to char")
+		}
+	case Delete:
+		if from == 0 || to != 0 {
+			return fmt.Errorf("delete must have only from char")
+		}
+	case Duplicate:
+		if from != 0 || to == 0 || prescription.previousChar(prescription.Tos, edit.Steps[0]) != to {
+			return fmt.Errorf("duplicate of %q doesn't follow the same char", to)
+		}
+	case MissDouble:
+		if from == 0 || to != 0 || prescription.previousChar(prescription.Froms, edit.Steps[0]) != from {
+			return fmt.Errorf("miss double of %q doesn't follow the same char", from)
+		}
+	case Transposition:
+		if len(edit.Steps) != 2 {
+			return fmt.Errorf("transposition has no pair")
+		}
+		if edit.Froms[0] == 0 || edit.Froms[1] == 0 || edit.Froms[0] == edit.Froms[1] ||
+			edit.Froms[0] != edit.Tos[1] || edit.Froms[1] != edit.Tos[0] {
+			return fmt.Errorf("%q isn't transposition of %q", string(edit.Tos), string(edit.Froms))
+		}
+		for k := edit.Steps[0] + 1; k < edit.Steps[1]; k++ {
+			if action := prescription.Actions[k]; action != Insert && action != Delete {
+				return fmt.Errorf("transposed chars are separated by %s", action)
+			}
+		}
+	case Triplet:
+		if len(edit.Steps) != 3 {
+			return fmt.Errorf("triplet has %d steps", len(edit.Steps))
+		}
+		froms, tos := edit.Froms, edit.Tos
+		if froms[0] == froms[1] || froms[0] == froms[2] || froms[1] == froms[2] {
+			return fmt.Errorf("triplet chars %q aren't pairwise different", string(froms))
+		}
+		isLeftRotation := tos[0] == froms[1] && tos[1] == froms[2] && tos[2] == froms[0]
+		isRightRotation := tos[0] == froms[2] && tos[1] == froms[0] && tos[2] == froms[1]
+		if !isLeftRotation && !isRightRotation {
+			return fmt.Errorf("%q isn't rotation of %q", string(tos), string(froms))
+		}
+	default:
+		return fmt.Errorf("unknown action %s", edit.Action)
+	}
+	return nil
+}
+
+// last char before step, 0 if there is none
+func (prescription *EditorialPrescription) previousChar(chars []rune, step int) rune {
+	for k := step - 1; k >= 0; k-- {
+		if chars[k] != 0 {
+			return chars[k]
+		}
+	}
+	return 0
+}
+
+func joinChars(chars []rune) string {
+	var builder strings.Builder
+	for _, char := range chars {
+		if char != 0 {
+			builder.WriteRune(char)
+		}
+	}
+	return builder.String()
+}
@@ -1,39 +1,218 @@
 package spell
 
-import "fmt"
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+)
 
-func (prescription *EditorialPrescription) Dump() {
+var editActionNames = map[EditAction]string{
+	Insert:        "insert",
+	Delete:        "delete",
+	Replace:       "replace",
+	Match:         "match",
+	Transposition: "transposition",
+	Duplicate:     "duplicate",
+	MissDouble:    "miss-double",
+	Triplet:       "triplet",
+}
+
+var editActionLetters = map[EditAction]string{
+	Insert:        "I",
+	Delete:        "D",
+	Replace:       "R",
+	Match:         "M",
+	Transposition: "T",
+	MissDouble:    "U",
+	Duplicate:     "P",
+	Triplet:       "J",
+}
+
+// ANSI colors used by RenderAlignment
+var editActionColors = map[EditAction]string{
+	Insert:        "\x1b[32m",
+	Delete:        "\x1b[31m",
+	Replace:       "\x1b[33m",
+	Transposition: "\x1b[35m",
+	Triplet:       "\x1b[35m",
+	Duplicate:     "\x1b[36m",
+	MissDouble:    "\x1b[36m",
+}
+
+const colorReset = "\x1b[0m"
+
+func (action EditAction) String() string {
+	if name, ok := editActionNames[action]; ok {
+		return name
+	}
+	return fmt.Sprintf("EditAction(%d)", uint32(action))
+}
+
+func (action EditAction) MarshalText() ([]byte, error) {
+	if name, ok := editActionNames[action]; ok {
+		return []byte(name), nil
+	}
+	return nil, fmt.Errorf("unknown edit action %d", uint32(action))
+}
+
+func (action *EditAction) UnmarshalText(text []byte) error {
+	for knownAction, name := range editActionNames {
+		if name == string(text) {
+			*action = knownAction
+			return nil
+		}
+	}
+	return fmt.Errorf("unknown edit action %q", string(text))
+}
+
+type prescriptionStep struct {
+	Action EditAction `json:"action"`
+	From   string     `json:"from,omitempty"`
+	To     string     `json:"to,omitempty"`
+}
+
+/**
+	Prescription is marshaled as the list of steps, e.g. [{"action":"replace","from":"e","to":"a"}]
+ */
+func (prescription *EditorialPrescription) MarshalJSON() ([]byte, error) {
+	steps := make([]prescriptionStep, len(prescription.Actions))
+	for i, action := range prescription.Actions {
+		steps[i] = prescriptionStep{
+			Action: action,
+			From:   charString(prescription.Froms[i]),
+			To:     charString(prescription.Tos[i]),
+		}
+	}
+	return json.Marshal(steps)
+}
+
+func (prescription *EditorialPrescription) UnmarshalJSON(data []byte) error {
+	var steps []prescriptionStep
+	if err := json.Unmarshal(data, &steps); err != nil {
+		return err
+	}
+	prescription.Actions = make([]EditAction, len(steps))
+	prescription.Froms = make([]rune, len(steps))
+	prescription.Tos = make([]rune, len(steps))
+	for i, step := range steps {
+		var err error
+		prescription.Actions[i] = step.Action
+		if prescription.Froms[i], err = stringChar(step.From); err != nil {
+			return err
+		}
+		if prescription.Tos[i], err = stringChar(step.To); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+/**
+	One line description, e.g. "match(t) replace(e→a) insert(→s)"
+ */
+func (prescription *EditorialPrescription) String() string {
+	steps := make([]string, len(prescription.Actions))
+	for i, action := range prescription.Actions {
+		if action == Match {
+			steps[i] = fmt.Sprintf("%s(%s)", action, charString(prescription.Froms[i]))
+		} else {
+			steps[i] = fmt.Sprintf("%s(%s→%s)", action, charString(prescription.Froms[i]), charString(prescription.Tos[i]))
+		}
+	}
+	return strings.Join(steps, " ")
+}
+
+/**
+	Write action letters, term chars and input chars line by line
+ */
+func (prescription *EditorialPrescription) Dump(w io.Writer) error {
+	var builder strings.Builder
 	for i := 0; i < len(prescription.Actions); i++ {
-		action := ""
-		switch prescription.Actions[i] {
-		case Insert:
-			action = "I"
-		case Delete:
-			action = "D"
-		case Replace:
-			action = "R"
-		case Match:
-			action = "M"
-		case Transposition:
-			action = "T"
-		case MissDouble:
-			action = "U"
-		case Duplicate:
-			action = "P"
-		case Triplet:
-			action = "J"
-		}
-		fmt.Print(action, " ")
-	}
-	fmt.Println()
+		builder.WriteString(editActionLetters[prescription.Actions[i]])
+		builder.WriteString(" ")
+	}
+	builder.WriteString("\n")
 
 	for i := 0; i < len(prescription.Froms); i++ {
-		fmt.Print(string(prescription.Froms[i]), " ")
+		builder.WriteString(string(prescription.Froms[i]))
+		builder.WriteString(" ")
 	}
-	fmt.Println()
+	builder.WriteString("\n")
 
 	for i := 0; i < len(prescription.Tos); i++ {
-		fmt.Print(string(prescription.Tos[i]), " ")
+		builder.WriteString(string(prescription.Tos[i]))
+		builder.WriteString(" ")
+	}
+	builder.WriteString("\n")
+
+	_, err := io.WriteString(w, builder.String())
+	return err
+}
+
+/**
+	Write term and input aligned side by side with edited columns marked by action letters, e.g.
+
+	r e c i e v e
+	      T T
+	r e c e i v e
+
+	Missing chars are shown as "-", colored output uses ANSI escape codes
+ */
+func (prescription *EditorialPrescription) RenderAlignment(w io.Writer, colored bool) error {
+	var (
+		fromsLine   strings.Builder
+		actionsLine strings.Builder
+		tosLine     strings.Builder
+	)
+	for i, action := range prescription.Actions {
+		var (
+			from   = alignmentChar(prescription.Froms[i])
+			to     = alignmentChar(prescription.Tos[i])
+			letter = " "
+		)
+		if action != Match {
+			letter = editActionLetters[action]
+		}
+		if color, ok := editActionColors[action]; ok && colored {
+			from = color + from + colorReset
+			to = color + to + colorReset
+			letter = color + letter + colorReset
+		}
+		if i > 0 {
+			fromsLine.WriteString(" ")
+			actionsLine.WriteString(" ")
+			tosLine.WriteString(" ")
+		}
+		fromsLine.WriteString(from)
+		actionsLine.WriteString(letter)
+		tosLine.WriteString(to)
+	}
+	_, err := fmt.Fprintf(w, "%s\n%s\n%s\n", fromsLine.String(), strings.TrimRight(actionsLine.String(), " "), tosLine.String())
+	return err
+}
+
+func charString(char rune) string {
+	if char == 0 {
+		return ""
+	}
+	return string(char)
+}
+
+func stringChar(s string) (rune, error) {
+	chars := []rune(s)
+	switch len(chars) {
+	case 0:
+		return 0, nil
+	case 1:
+		return chars[0], nil
+	}
+	return 0, fmt.Errorf("prescription step char %q must be a single char", s)
+}
+
+func alignmentChar(char rune) string {
+	if char == 0 {
+		return "-"
 	}
-	fmt.Println()
+	return string(char)
 }
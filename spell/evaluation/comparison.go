@@ -0,0 +1,59 @@
+package evaluation
+
+import (
+	"fmt"
+	"io"
+	"spell"
+	"text/tabwriter"
+)
+
+type comparisonRow struct {
+	name                                string
+	expectedValue, expectedContribution float64
+	winnerValue, winnerContribution     float64
+}
+
+/**
+	WriteComparison writes feature contributions of the expected suggestion next to the ones of the winner,
+	contributions of the same name are summed up
+ */
+func WriteComparison(w io.Writer, explainer spell.ScoreExplainer, expected, winner *spell.Suggestion) error {
+	var (
+		rows   []*comparisonRow
+		byName = map[string]*comparisonRow{}
+	)
+	row := func(name string) *comparisonRow {
+		if result, ok := byName[name]; ok {
+			return result
+		}
+		result := &comparisonRow{name: name}
+		byName[name] = result
+		rows = append(rows, result)
+		return result
+	}
+	var expectedTotal, winnerTotal float64
+	for _, contribution := range explainer.ExplainScore(expected) {
+		current := row(contribution.Name)
+		current.expectedValue += contribution.Value
+		current.expectedContribution += contribution.Contribution
+		expectedTotal += contribution.Contribution
+	}
+	for _, contribution := range explainer.ExplainScore(winner) {
+		current := row(contribution.Name)
+		current.winnerValue += contribution.Value
+		current.winnerContribution += contribution.Contribution
+		winnerTotal += contribution.Contribution
+	}
+
+	tw := tabwriter.NewWriter(w, 0, 8, 2, ' ', tabwriter.AlignRight)
+	fmt.Fprintf(tw, "\texpected %s\t\twinner %s\t\t\t\n", expected.Term, winner.Term)
+	fmt.Fprintf(tw, "feature\tvalue\tcontribution\tvalue\tcontribution\tdifference\t\n")
+	for _, current := range rows {
+		fmt.Fprintf(tw, "%s\t%.4g\t%.4f\t%.4g\t%.4f\t%+.4f\t\n", current.name,
+			current.expectedValue, current.expectedContribution,
+			current.winnerValue, current.winnerContribution,
+			current.expectedContribution-current.winnerContribution)
+	}
+	fmt.Fprintf(tw, "total\t\t%.4f\t\t%.4f\t%+.4f\t\n", expectedTotal, winnerTotal, expectedTotal-winnerTotal)
+	return tw.Flush()
+}
@@ -0,0 +1,123 @@
+package evaluation
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"math"
+	"sort"
+	"spell"
+	"text/tabwriter"
+)
+
+type CrossValidationReport struct {
+	K        int                           `json:"k"`
+	Folds    []*Report                     `json:"folds"`
+	Mean     Metrics                       `json:"mean"`
+	StdDev   Metrics                       `json:"std_dev"`
+	ByAction map[spell.EditAction]*Metrics `json:"by_action"` // means over folds
+}
+
+/**
+	CrossValidator trains the learner on the train part of every split and evaluates it on the test part
+ */
+type CrossValidator struct {
+	Evaluator *Evaluator
+	OnFold    func(fold int, report *Report)
+}
+
+func InitCrossValidator(evaluator *Evaluator) *CrossValidator {
+	return &CrossValidator{
+		Evaluator: evaluator,
+	}
+}
+
+func (validator *CrossValidator) Run(learner spell.Learner, splits []*Split) *CrossValidationReport {
+	report := &CrossValidationReport{
+		K:        validator.Evaluator.K,
+		ByAction: map[spell.EditAction]*Metrics{},
+	}
+	for i, split := range splits {
+		scoreModel := learner.Learn(split.Train)
+		foldReport := validator.Evaluator.Evaluate(scoreModel, split.Test)
+		report.Folds = append(report.Folds, foldReport)
+		if validator.OnFold != nil {
+			validator.OnFold(i, foldReport)
+		}
+	}
+
+	folds := make([]*Metrics, len(report.Folds))
+	actionFolds := map[spell.EditAction][]*Metrics{}
+	for i, foldReport := range report.Folds {
+		folds[i] = &foldReport.Metrics
+		for action, metrics := range foldReport.ByAction {
+			actionFolds[action] = append(actionFolds[action], metrics)
+		}
+	}
+	report.Mean, report.StdDev = aggregate(folds)
+	for action, metrics := range actionFolds {
+		mean, _ := aggregate(metrics)
+		report.ByAction[action] = &mean
+	}
+	return report
+}
+
+/**
+	Mean and standard deviation of every metric over folds, counts are summed
+ */
+func aggregate(folds []*Metrics) (mean Metrics, stdDev Metrics) {
+	if len(folds) == 0 {
+		return
+	}
+	n := float64(len(folds))
+	for _, metrics := range folds {
+		mean.Count += metrics.Count
+		mean.AccuracyAt1 += metrics.AccuracyAt1 / n
+		mean.AccuracyAtK += metrics.AccuracyAtK / n
+		mean.MRR += metrics.MRR / n
+		mean.NotCandidate += metrics.NotCandidate / n
+	}
+	stdDev.Count = mean.Count
+	for _, metrics := range folds {
+		stdDev.AccuracyAt1 += (metrics.AccuracyAt1 - mean.AccuracyAt1) * (metrics.AccuracyAt1 - mean.AccuracyAt1) / n
+		stdDev.AccuracyAtK += (metrics.AccuracyAtK - mean.AccuracyAtK) * (metrics.AccuracyAtK - mean.AccuracyAtK) / n
+		stdDev.MRR += (metrics.MRR - mean.MRR) * (metrics.MRR - mean.MRR) / n
+		stdDev.NotCandidate += (metrics.NotCandidate - mean.NotCandidate) * (metrics.NotCandidate - mean.NotCandidate) / n
+	}
+	stdDev.AccuracyAt1 = math.Sqrt(stdDev.AccuracyAt1)
+	stdDev.AccuracyAtK = math.Sqrt(stdDev.AccuracyAtK)
+	stdDev.MRR = math.Sqrt(stdDev.MRR)
+	stdDev.NotCandidate = math.Sqrt(stdDev.NotCandidate)
+	return
+}
+
+/**
+	A line per fold, the mean and the standard deviation, followed by per action means
+ */
+func (report *CrossValidationReport) WriteTable(w io.Writer) error {
+	tw := tabwriter.NewWriter(w, 0, 8, 2, ' ', tabwriter.AlignRight)
+	fmt.Fprintf(tw, "\tcount\tacc@1\tacc@%d\tmrr\tnot candidate\t\n", report.K)
+	for i, foldReport := range report.Folds {
+		writeRow(tw, fmt.Sprintf("fold %d", i+1), &foldReport.Metrics)
+	}
+	writeRow(tw, "mean", &report.Mean)
+	writeRow(tw, "std dev", &report.StdDev)
+
+	actions := make([]spell.EditAction, 0, len(report.ByAction))
+	for action := range report.ByAction {
+		actions = append(actions, action)
+	}
+	sort.Slice(actions, func(i, j int) bool {
+		return actions[i] < actions[j]
+	})
+	for _, action := range actions {
+		writeRow(tw, action.String(), report.ByAction[action])
+	}
+	return tw.Flush()
+}
+
+func (report *CrossValidationReport) WriteJSON(w io.Writer) error {
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(report)
+}
@@ -0,0 +1,192 @@
+package evaluation
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"spell"
+	"text/tabwriter"
+)
+
+/**
+	Metrics of a set of cases, a case is a misspell with its correct term.
+	A case whose correct term isn't among the candidates has no rank, it counts as a miss in every metric
+ */
+type Metrics struct {
+	Count        int     `json:"count"`
+	AccuracyAt1  float64 `json:"accuracy_at_1"`
+	AccuracyAtK  float64 `json:"accuracy_at_k"`
+	MRR          float64 `json:"mrr"`
+	NotCandidate float64 `json:"not_candidate"`
+
+	hitsAt1       int
+	hitsAtK       int
+	reciprocalSum float64
+	notCandidates int
+}
+
+type Report struct {
+	K int `json:"k"`
+	Metrics
+	ByAction map[spell.EditAction]*Metrics `json:"by_action"`
+}
+
+/**
+	Evaluator ranks candidates of every learning term with a score model.
+	Candidates come from the Model if it is set, otherwise the suggestions of the learning term are ranked
+ */
+type Evaluator struct {
+	Model  *spell.Model
+	K      int
+	OnMiss func(learningTerm *spell.LearningTerm, suggestions []spell.Suggestion) // called when the top suggestion isn't the correct term
+}
+
+func InitEvaluator(model *spell.Model) *Evaluator {
+	return &Evaluator{
+		Model: model,
+		K:     5,
+	}
+}
+
+func (evaluator *Evaluator) Evaluate(scoreModel spell.ScoreModel, learningData []*spell.LearningTerm) *Report {
+	report := &Report{
+		K:        evaluator.K,
+		ByAction: map[spell.EditAction]*Metrics{},
+	}
+	measurer := spell.NewDistanceMeasurer()
+	for _, learningTerm := range learningData {
+		suggestions := evaluator.rank(scoreModel, learningTerm)
+		rank := 0
+		for i := range suggestions {
+			if suggestions[i].Term == learningTerm.Term {
+				rank = i + 1
+				break
+			}
+		}
+		if rank != 1 && evaluator.OnMiss != nil {
+			evaluator.OnMiss(learningTerm, suggestions)
+		}
+
+		report.add(rank, evaluator.K)
+		for _, action := range caseActions(measurer, learningTerm, suggestions) {
+			metrics, ok := report.ByAction[action]
+			if !ok {
+				metrics = &Metrics{}
+				report.ByAction[action] = metrics
+			}
+			metrics.add(rank, evaluator.K)
+		}
+	}
+	report.finish()
+	for _, metrics := range report.ByAction {
+		metrics.finish()
+	}
+	return report
+}
+
+func (evaluator *Evaluator) rank(scoreModel spell.ScoreModel, learningTerm *spell.LearningTerm) []spell.Suggestion {
+	if evaluator.Model != nil {
+		return evaluator.Model.GetSuggestions(learningTerm.Misspell, scoreModel, true)
+	}
+	suggestions := make([]spell.Suggestion, len(learningTerm.Suggestions))
+	copy(suggestions, learningTerm.Suggestions)
+	spell.ScoreSuggestions(scoreModel, suggestions)
+	sort.SliceStable(suggestions, func(i, j int) bool {
+		if suggestions[i].Score != suggestions[j].Score {
+			return suggestions[i].Score < suggestions[j].Score
+		}
+		return suggestions[i].Term < suggestions[j].Term
+	})
+	return suggestions
+}
+
+/**
+	Distinct edit actions turning the correct term into the misspell, a case is counted once per action.
+	The prescription of the correct candidate is used if there is one, otherwise it is measured
+ */
+func caseActions(measurer *spell.DistanceMeasurer, learningTerm *spell.LearningTerm, suggestions []spell.Suggestion) []spell.EditAction {
+	var prescription *spell.EditorialPrescription
+	for i := range suggestions {
+		if suggestions[i].Term == learningTerm.Term {
+			prescription = suggestions[i].Prescription
+			break
+		}
+	}
+	if prescription == nil {
+		_, prescription = measurer.Distance(learningTerm.Term, learningTerm.Misspell, true)
+	}
+	if prescription == nil {
+		return nil
+	}
+
+	var (
+		actions []spell.EditAction
+		seen    = map[spell.EditAction]bool{}
+	)
+	for _, edit := range prescription.Edits() {
+		if edit.Action == spell.Match || seen[edit.Action] {
+			continue
+		}
+		seen[edit.Action] = true
+		actions = append(actions, edit.Action)
+	}
+	return actions
+}
+
+func (metrics *Metrics) add(rank, k int) {
+	metrics.Count++
+	if rank == 0 {
+		metrics.notCandidates++
+		return
+	}
+	if rank == 1 {
+		metrics.hitsAt1++
+	}
+	if rank <= k {
+		metrics.hitsAtK++
+	}
+	metrics.reciprocalSum += 1 / float64(rank)
+}
+
+func (metrics *Metrics) finish() {
+	if metrics.Count == 0 {
+		return
+	}
+	count := float64(metrics.Count)
+	metrics.AccuracyAt1 = float64(metrics.hitsAt1) / count
+	metrics.AccuracyAtK = float64(metrics.hitsAtK) / count
+	metrics.MRR = metrics.reciprocalSum / count
+	metrics.NotCandidate = float64(metrics.notCandidates) / count
+}
+
+/**
+	Overall metrics followed by a line per edit action
+ */
+func (report *Report) WriteTable(w io.Writer) error {
+	tw := tabwriter.NewWriter(w, 0, 8, 2, ' ', tabwriter.AlignRight)
+	fmt.Fprintf(tw, "\tcount\tacc@1\tacc@%d\tmrr\tnot candidate\t\n", report.K)
+	writeRow(tw, "all", &report.Metrics)
+
+	actions := make([]spell.EditAction, 0, len(report.ByAction))
+	for action := range report.ByAction {
+		actions = append(actions, action)
+	}
+	sort.Slice(actions, func(i, j int) bool {
+		return actions[i] < actions[j]
+	})
+	for _, action := range actions {
+		writeRow(tw, action.String(), report.ByAction[action])
+	}
+	return tw.Flush()
+}
+
+func writeRow(w io.Writer, name string, metrics *Metrics) {
+	fmt.Fprintf(w, "%s\t%d\t%.4f\t%.4f\t%.4f\t%.4f\t\n", name, metrics.Count, metrics.AccuracyAt1, metrics.AccuracyAtK, metrics.MRR, metrics.NotCandidate)
+}
+
+func (report *Report) WriteJSON(w io.Writer) error {
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(report)
+}
@@ -0,0 +1,270 @@
+package evaluation
+
+import (
+	"math"
+	"math/rand"
+	"reflect"
+	"sort"
+	"spell"
+	"spell/scorer"
+	"spell/scorer/linear"
+	"testing"
+)
+
+var fixtureMisspells = map[string][]string{
+	"hello":   {"helo", "hallo"},
+	"help":    {"halp", "hep"},
+	"world":   {"wrold", "wold"},
+	"word":    {"wrd", "ward"},
+	"spell":   {"spel", "speel"},
+	"speller": {"speler"},
+	"text":    {"tect", "tex"},
+	"tent":    {"tetn"},
+	"card":    {"crad", "cadr"},
+	"cart":    {"catr"},
+}
+
+/**
+	Learning terms of the fixture misspells in the order of terms
+ */
+func fixtureLearningData() (*spell.Model, []*spell.LearningTerm) {
+	terms := make([]string, 0, len(fixtureMisspells))
+	for term := range fixtureMisspells {
+		terms = append(terms, term)
+	}
+	sort.Strings(terms)
+	model := spell.InitModel()
+	model.TrainTerms(terms)
+
+	learningData := make([]*spell.LearningTerm, 0)
+	for _, term := range terms {
+		for _, misspell := range fixtureMisspells[term] {
+			if learningTerm := model.GetLearningTerm(term, misspell); learningTerm != nil {
+				learningData = append(learningData, learningTerm)
+			}
+		}
+	}
+	return model, learningData
+}
+
+type constantScorer struct{}
+
+func (constantScorer) Score(a *spell.Suggestion) float64 {
+	return 1
+}
+
+func (constantScorer) Compare(a *spell.Suggestion, b *spell.Suggestion) float64 {
+	return 0
+}
+
+func TestEvaluateIsDeterministic(t *testing.T) {
+	model := spell.InitModel()
+	model.TrainTerms([]string{"hello", "help", "held", "hell", "word", "world", "wore", "worm"})
+	learningData := []*spell.LearningTerm{
+		{Term: "hello", Misspell: "helo"},
+		{Term: "help", Misspell: "hel"},
+		{Term: "word", Misspell: "wor"},
+		{Term: "worm", Misspell: "wrm"},
+	}
+	evaluator := InitEvaluator(model)
+	expected := evaluator.Evaluate(constantScorer{}, learningData)
+	for i := 0; i < 20; i++ {
+		if report := evaluator.Evaluate(constantScorer{}, learningData); !reflect.DeepEqual(report, expected) {
+			t.Fatalf("run %d reported %+v, the first run reported %+v", i+2, report.Metrics, expected.Metrics)
+		}
+	}
+}
+
+func TestSameSeedGivesSameSplitsAndWeights(t *testing.T) {
+	_, learningData := fixtureLearningData()
+	run := func(seed int64) ([]*Split, [][]float64) {
+		source := rand.New(rand.NewSource(seed))
+		splitter := InitSplitter(source)
+		splitter.Stratified = true
+		splits, err := splitter.KFold(learningData, 3)
+		if err != nil {
+			t.Fatal(err)
+		}
+		weights := make([][]float64, 0, len(splits))
+		for _, split := range splits {
+			learner := &linear.Learner{Vectoriser: scorer.InitVectoriser(), Seed: seed, Source: source}
+			weights = append(weights, learner.Learn(split.Train).(*linear.Scorer).Weights.Xs)
+		}
+		return splits, weights
+	}
+	splits, weights := run(5)
+	otherSplits, otherWeights := run(5)
+	if !reflect.DeepEqual(splits, otherSplits) {
+		t.Error("splits of the same seed differ")
+	}
+	if !reflect.DeepEqual(weights, otherWeights) {
+		t.Errorf("weights of the same seed differ: %v and %v", weights, otherWeights)
+	}
+}
+
+type termScorer map[string]float64
+
+func (scores termScorer) Score(a *spell.Suggestion) float64 {
+	return scores[a.Term]
+}
+
+func (scores termScorer) Compare(a *spell.Suggestion, b *spell.Suggestion) float64 {
+	return scores[b.Term] - scores[a.Term]
+}
+
+func suggestionsOf(terms ...string) []spell.Suggestion {
+	suggestions := make([]spell.Suggestion, 0, len(terms))
+	for _, term := range terms {
+		suggestions = append(suggestions, spell.Suggestion{Term: term})
+	}
+	return suggestions
+}
+
+func TestEvaluateMetrics(t *testing.T) {
+	var (
+		scores      = termScorer{"text": 1, "tent": 2, "test": 3}
+		first       = &spell.LearningTerm{Term: "text", Misspell: "tect", Suggestions: suggestionsOf("tent", "text")}
+		second      = &spell.LearningTerm{Term: "tent", Misspell: "tect", Suggestions: suggestionsOf("tent", "text")}
+		third       = &spell.LearningTerm{Term: "test", Misspell: "tect", Suggestions: suggestionsOf("test", "tent", "text")}
+		notIncluded = &spell.LearningTerm{Term: "tact", Misspell: "tect", Suggestions: suggestionsOf("tent", "text")}
+		empty       = &spell.LearningTerm{Term: "text", Misspell: "tect"}
+	)
+	cases := []struct {
+		name         string
+		learningData []*spell.LearningTerm
+		expected     Metrics
+	}{
+		{"first", []*spell.LearningTerm{first}, Metrics{Count: 1, AccuracyAt1: 1, AccuracyAtK: 1, MRR: 1}},
+		{"second", []*spell.LearningTerm{second}, Metrics{Count: 1, AccuracyAtK: 1, MRR: 0.5}},
+		{"beyond k", []*spell.LearningTerm{third}, Metrics{Count: 1, MRR: 1.0 / 3}},
+		{"not a candidate", []*spell.LearningTerm{notIncluded}, Metrics{Count: 1, NotCandidate: 1}},
+		{"no suggestions", []*spell.LearningTerm{empty}, Metrics{Count: 1, NotCandidate: 1}},
+		{"all", []*spell.LearningTerm{first, second, third, notIncluded}, Metrics{
+			Count:        4,
+			AccuracyAt1:  0.25,
+			AccuracyAtK:  0.5,
+			MRR:          (1 + 0.5 + 1.0/3) / 4,
+			NotCandidate: 0.25,
+		}},
+		{"none", nil, Metrics{}},
+	}
+	evaluator := &Evaluator{K: 2}
+	for _, c := range cases {
+		report := evaluator.Evaluate(scores, c.learningData)
+		metrics := report.Metrics
+		if metrics.Count != c.expected.Count ||
+			math.Abs(metrics.AccuracyAt1-c.expected.AccuracyAt1) > 1e-9 ||
+			math.Abs(metrics.AccuracyAtK-c.expected.AccuracyAtK) > 1e-9 ||
+			math.Abs(metrics.MRR-c.expected.MRR) > 1e-9 ||
+			math.Abs(metrics.NotCandidate-c.expected.NotCandidate) > 1e-9 {
+			t.Errorf("%s: metrics are %+v, expected %+v", c.name, metrics, c.expected)
+		}
+		// every case misspells by one replacement
+		if len(c.learningData) > 0 && (len(report.ByAction) != 1 || report.ByAction[spell.Replace].Count != metrics.Count) {
+			t.Errorf("%s: metrics by action are %v, expected all the cases under replace", c.name, report.ByAction)
+		}
+	}
+}
+
+func termsOf(learningData []*spell.LearningTerm) map[string]int {
+	terms := map[string]int{}
+	for _, learningTerm := range learningData {
+		terms[learningTerm.Term]++
+	}
+	return terms
+}
+
+/**
+	Parts must share no term and together hold every learning term exactly once
+ */
+func checkSplit(t *testing.T, name string, split *Split, learningData []*spell.LearningTerm) {
+	trainTerms, testTerms := termsOf(split.Train), termsOf(split.Test)
+	for term := range testTerms {
+		if trainTerms[term] > 0 {
+			t.Errorf("%s: %s is both in train and test parts", name, term)
+		}
+	}
+	if len(split.Train)+len(split.Test) != len(learningData) {
+		t.Errorf("%s: parts have %d+%d learning terms, expected %d", name, len(split.Train), len(split.Test), len(learningData))
+	}
+	for term, count := range termsOf(learningData) {
+		if trainTerms[term]+testTerms[term] != count {
+			t.Errorf("%s: %s has %d learning terms in the parts, expected %d", name, term, trainTerms[term]+testTerms[term], count)
+		}
+	}
+}
+
+func TestHoldout(t *testing.T) {
+	_, learningData := fixtureLearningData()
+	for _, stratified := range []bool{false, true} {
+		splitter := InitSplitter(rand.New(rand.NewSource(1)))
+		splitter.Stratified = stratified
+		split, err := splitter.Holdout(learningData, 0.3)
+		if err != nil {
+			t.Fatal(err)
+		}
+		checkSplit(t, "holdout", split, learningData)
+		if len(split.Test) == 0 || len(split.Train) == 0 {
+			t.Errorf("holdout has %d train and %d test learning terms", len(split.Train), len(split.Test))
+		}
+	}
+	for _, testShare := range []float64{0, 1, -0.5} {
+		if _, err := InitSplitter(rand.New(rand.NewSource(1))).Holdout(learningData, testShare); err == nil {
+			t.Errorf("test share %v is accepted", testShare)
+		}
+	}
+}
+
+func TestKFold(t *testing.T) {
+	_, learningData := fixtureLearningData()
+	for _, k := range []int{2, 3, len(fixtureMisspells)} {
+		for _, stratified := range []bool{false, true} {
+			splitter := InitSplitter(rand.New(rand.NewSource(1)))
+			splitter.Stratified = stratified
+			splits, err := splitter.KFold(learningData, k)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(splits) != k {
+				t.Fatalf("%d splits, expected %d", len(splits), k)
+			}
+			tested := map[string]int{}
+			for _, split := range splits {
+				checkSplit(t, "k-fold", split, learningData)
+				if len(split.Test) == 0 {
+					t.Errorf("%d folds: a fold has no test terms", k)
+				}
+				for term := range termsOf(split.Test) {
+					tested[term]++
+				}
+			}
+			for term := range fixtureMisspells {
+				if tested[term] != 1 {
+					t.Errorf("%d folds: %s is tested %d times, expected once", k, term, tested[term])
+				}
+			}
+		}
+	}
+	for _, k := range []int{1, len(fixtureMisspells) + 1} {
+		if _, err := InitSplitter(rand.New(rand.NewSource(1))).KFold(learningData, k); err == nil {
+			t.Errorf("%d folds are accepted for %d terms", k, len(fixtureMisspells))
+		}
+	}
+}
+
+func TestSplitDependsOnSeed(t *testing.T) {
+	_, learningData := fixtureLearningData()
+	split := func(seed int64) []*Split {
+		splits, err := InitSplitter(rand.New(rand.NewSource(seed))).KFold(learningData, 3)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return splits
+	}
+	if !reflect.DeepEqual(split(1), split(1)) {
+		t.Error("splits of the same seed differ")
+	}
+	if reflect.DeepEqual(split(1), split(2)) {
+		t.Error("splits of seeds 1 and 2 are the same")
+	}
+}
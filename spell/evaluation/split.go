@@ -0,0 +1,169 @@
+package evaluation
+
+import (
+	"fmt"
+	"math/rand"
+	"sort"
+	"spell"
+)
+
+type Split struct {
+	Train []*spell.LearningTerm
+	Test  []*spell.LearningTerm
+}
+
+/**
+	Splitter splits learning data by correct term, all misspells of a term end up on the same side.
+	If Stratified is set terms are split separately per misspelling distance, a term goes to the stratum
+	of the distance most of its misspells have.
+	Terms are shuffled with Source, runs taking their sources from the same seed split the same
+ */
+type Splitter struct {
+	Source     *rand.Rand
+	Stratified bool
+}
+
+func InitSplitter(source *rand.Rand) *Splitter {
+	return &Splitter{
+		Source: source,
+	}
+}
+
+type termGroup struct {
+	learningTerms []*spell.LearningTerm
+	stratum       int
+}
+
+/**
+	Holdout puts about testShare of learning terms into the test part
+ */
+func (splitter *Splitter) Holdout(learningData []*spell.LearningTerm, testShare float64) (*Split, error) {
+	if testShare <= 0 || testShare >= 1 {
+		return nil, fmt.Errorf("test share must be in (0, 1), got %v", testShare)
+	}
+	split := &Split{}
+	for _, groups := range splitter.strata(learningData) {
+		var (
+			total    = 0
+			selected = 0
+		)
+		for _, group := range groups {
+			total += len(group.learningTerms)
+		}
+		target := testShare * float64(total)
+		for _, group := range groups {
+			if float64(selected) < target {
+				split.Test = append(split.Test, group.learningTerms...)
+				selected += len(group.learningTerms)
+			} else {
+				split.Train = append(split.Train, group.learningTerms...)
+			}
+		}
+	}
+	return split, nil
+}
+
+/**
+	KFold returns k splits, every learning term is in the test part of exactly one of them
+ */
+func (splitter *Splitter) KFold(learningData []*spell.LearningTerm, k int) ([]*Split, error) {
+	if k < 2 {
+		return nil, fmt.Errorf("k must be at least 2, got %d", k)
+	}
+	var (
+		strata = splitter.strata(learningData)
+		folds  = make([][]*spell.LearningTerm, k)
+		groups = 0
+	)
+	for _, stratumGroups := range strata {
+		groups += len(stratumGroups)
+		// a group goes to the fold having the least learning terms of the stratum
+		counts := make([]int, k)
+		for _, group := range stratumGroups {
+			fold := 0
+			for i := 1; i < k; i++ {
+				if counts[i] < counts[fold] {
+					fold = i
+				}
+			}
+			folds[fold] = append(folds[fold], group.learningTerms...)
+			counts[fold] += len(group.learningTerms)
+		}
+	}
+	if groups < k {
+		return nil, fmt.Errorf("k is %d but there are only %d distinct terms", k, groups)
+	}
+
+	splits := make([]*Split, k)
+	for i := range splits {
+		split := &Split{Test: folds[i]}
+		for j := range folds {
+			if j != i {
+				split.Train = append(split.Train, folds[j]...)
+			}
+		}
+		splits[i] = split
+	}
+	return splits, nil
+}
+
+/**
+	Term groups shuffled with the source, per stratum in ascending order of strata
+ */
+func (splitter *Splitter) strata(learningData []*spell.LearningTerm) [][]*termGroup {
+	var (
+		groupsByTerm = map[string]*termGroup{}
+		terms        []string
+	)
+	for _, learningTerm := range learningData {
+		group, ok := groupsByTerm[learningTerm.Term]
+		if !ok {
+			group = &termGroup{}
+			groupsByTerm[learningTerm.Term] = group
+			terms = append(terms, learningTerm.Term)
+		}
+		group.learningTerms = append(group.learningTerms, learningTerm)
+	}
+	// map order is random, the source alone must define the split
+	sort.Strings(terms)
+
+	var (
+		measurer  = spell.NewDistanceMeasurer()
+		byStratum = map[int][]*termGroup{}
+		strata    []int
+	)
+	for _, term := range terms {
+		group := groupsByTerm[term]
+		if splitter.Stratified {
+			group.stratum = dominantDistance(measurer, group.learningTerms)
+		}
+		if _, ok := byStratum[group.stratum]; !ok {
+			strata = append(strata, group.stratum)
+		}
+		byStratum[group.stratum] = append(byStratum[group.stratum], group)
+	}
+	sort.Ints(strata)
+
+	result := make([][]*termGroup, 0, len(strata))
+	for _, stratum := range strata {
+		groups := byStratum[stratum]
+		splitter.Source.Shuffle(len(groups), func(i, j int) {
+			groups[i], groups[j] = groups[j], groups[i]
+		})
+		result = append(result, groups)
+	}
+	return result
+}
+
+func dominantDistance(measurer *spell.DistanceMeasurer, learningTerms []*spell.LearningTerm) int {
+	counts := map[int]int{}
+	dominant := -1
+	for _, learningTerm := range learningTerms {
+		distance, _ := measurer.Distance(learningTerm.Term, learningTerm.Misspell, false)
+		counts[distance]++
+		if dominant < 0 || counts[distance] > counts[dominant] || counts[distance] == counts[dominant] && distance < dominant {
+			dominant = distance
+		}
+	}
+	return dominant
+}
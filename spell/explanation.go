@@ -0,0 +1,114 @@
+package spell
+
+import (
+	"strconv"
+	"strings"
+)
+
+/**
+	Explanation describes one non-Match edit of a suggestion.
+	Positions are 0-based rune positions of the edit in the term and in the input
+ */
+type Explanation struct {
+	Action        EditAction
+	TermPosition  int
+	InputPosition int
+	Term          string // term chars affected by the edit
+	Input         string // input chars affected by the edit
+	Message       string
+}
+
+/**
+	Message templates may use {term}, {input} and {position} (1-based position in the term) placeholders
+ */
+var DefaultExplanationTemplates = map[EditAction]string{
+	Insert:        "extra letter '{input}' at position {position}",
+	Delete:        "missing letter '{term}' at position {position}",
+	Replace:       "'{input}' typed instead of '{term}' at position {position}",
+	Transposition: "letters '{term}' swapped at position {position}",
+	Duplicate:     "letter '{input}' doubled by mistake at position {position}",
+	MissDouble:    "missing doubled '{term}' at position {position}",
+	Triplet:       "letters '{term}' mixed up at position {position}",
+}
+
+type Explainer struct {
+	Templates map[EditAction]string
+}
+
+func NewExplainer() *Explainer {
+	return NewExplainerWithTemplates(DefaultExplanationTemplates)
+}
+
+/**
+	Make explainer with localized templates, actions without a template fall back to the default ones
+ */
+func NewExplainerWithTemplates(templates map[EditAction]string) *Explainer {
+	explainer := &Explainer{
+		Templates: make(map[EditAction]string, len(DefaultExplanationTemplates)),
+	}
+	for action, template := range DefaultExplanationTemplates {
+		explainer.Templates[action] = template
+	}
+	for action, template := range templates {
+		explainer.Templates[action] = template
+	}
+	return explainer
+}
+
+/**
+	Explain suggestion edits, exact matches and layout fixes have no prescription and so no explanations
+ */
+func (explainer *Explainer) ExplainSuggestion(suggestion *Suggestion) []Explanation {
+	if suggestion.Prescription == nil {
+		return nil
+	}
+	return explainer.Explain(suggestion.Prescription)
+}
+
+func (explainer *Explainer) Explain(prescription *EditorialPrescription) []Explanation {
+	var (
+		explanations = make([]Explanation, 0)
+		// term and input chars before every step
+		termPositions  = make([]int, len(prescription.Actions))
+		inputPositions = make([]int, len(prescription.Actions))
+		termPosition   = 0
+		inputPosition  = 0
+	)
+	for i := range prescription.Actions {
+		termPositions[i] = termPosition
+		inputPositions[i] = inputPosition
+		if prescription.Froms[i] != 0 {
+			termPosition++
+		}
+		if prescription.Tos[i] != 0 {
+			inputPosition++
+		}
+	}
+
+	for _, edit := range prescription.Edits() {
+		if edit.Action == Match {
+			continue
+		}
+		explanation := Explanation{
+			Action:        edit.Action,
+			TermPosition:  termPositions[edit.Steps[0]],
+			InputPosition: inputPositions[edit.Steps[0]],
+			Term:          joinChars(edit.Froms),
+			Input:         joinChars(edit.Tos),
+		}
+		explanation.Message = explanation.Format(explainer.Templates[edit.Action])
+		explanations = append(explanations, explanation)
+	}
+	return explanations
+}
+
+/**
+	Fill template placeholders with the explanation values
+ */
+func (explanation *Explanation) Format(template string) string {
+	return strings.NewReplacer(
+		"{term}", explanation.Term,
+		"{input}", explanation.Input,
+		"{position}", strconv.Itoa(explanation.TermPosition+1),
+	).Replace(template)
+}
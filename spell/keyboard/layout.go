@@ -0,0 +1,90 @@
+package keyboard
+
+import (
+	"math"
+	"unicode"
+)
+
+type KeyLocation struct {
+	X float64
+	Y float64
+}
+
+type Key struct {
+	Char     rune
+	Location KeyLocation
+}
+
+/**
+	Row of keys; the first key is placed at Offset, every next one is one key width further
+ */
+type Row struct {
+	Offset float64
+	Chars  string
+}
+
+/**
+	Layout represents keys geometry of a keyboard
+ */
+type Layout struct {
+	Name string
+	Keys []Key
+
+	locations map[rune]KeyLocation `binary:"-"`
+}
+
+func NewLayout(name string, keys []Key) *Layout {
+	layout := &Layout{
+		Name:      name,
+		Keys:      keys,
+		locations: make(map[rune]KeyLocation, len(keys)),
+	}
+	for _, key := range keys {
+		layout.locations[key.Char] = key.Location
+	}
+	return layout
+}
+
+/**
+	Make layout from rows, the row index is used as Y coordinate
+ */
+func NewRowsLayout(name string, rows []Row) *Layout {
+	keys := make([]Key, 0, 50)
+	for y, row := range rows {
+		for x, char := range []rune(row.Chars) {
+			keys = append(keys, Key{
+				Char:     char,
+				Location: KeyLocation{row.Offset + float64(x), float64(y)},
+			})
+		}
+	}
+	return NewLayout(name, keys)
+}
+
+func (layout *Layout) Location(char rune) (KeyLocation, bool) {
+	char = unicode.ToLower(char)
+	// layouts restored by reflection don't have the index
+	if layout.locations == nil {
+		for _, key := range layout.Keys {
+			if key.Char == char {
+				return key.Location, true
+			}
+		}
+		return KeyLocation{}, false
+	}
+	location, ok := layout.locations[char]
+	return location, ok
+}
+
+/**
+	Manhattan distance between keys, -1 if any of the chars isn't on the layout
+ */
+func (layout *Layout) Distance(from, to rune) float64 {
+	locationFrom, locationFromExists := layout.Location(from)
+	locationTo, locationToExists := layout.Location(to)
+
+	if locationFromExists && locationToExists {
+		return math.Abs(locationTo.X-locationFrom.X) + math.Abs(locationTo.Y-locationFrom.Y)
+	}
+	return -1
+}
@@ -0,0 +1,77 @@
+package keyboard
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+)
+
+/**
+	LayoutParser reads custom layouts in the following format:
+
+	# comment
+	name: colemak
+	-1 `1234567890-=
+	0.5 qwfpgjluy;[]
+	0.9 arstdhneio'
+	1.3 zxcvbkm,./
+
+	Every row line is an offset of the first key followed by the row chars, rows go from top to bottom
+ */
+type LayoutParser struct {
+}
+
+func NewLayoutParser() *LayoutParser {
+	return &LayoutParser{}
+}
+
+func (parser *LayoutParser) ParseFromFile(fileName string) (*Layout, error) {
+	fp, err := os.Open(fileName)
+	if err != nil {
+		return nil, err
+	}
+	defer fp.Close()
+	return parser.Parse(fp)
+}
+
+func (parser *LayoutParser) Parse(reader io.Reader) (*Layout, error) {
+	var (
+		scanner = bufio.NewScanner(reader)
+		name    string
+		rows    = make([]Row, 0, 4)
+		lineNo  = 0
+	)
+	for scanner.Scan() {
+		lineNo++
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || line[0] == '#' {
+			continue
+		}
+		if strings.HasPrefix(line, "name:") {
+			name = strings.TrimSpace(line[len("name:"):])
+			continue
+		}
+		fields := strings.Fields(line)
+		if len(fields) != 2 {
+			return nil, fmt.Errorf("keyboard layout line %d: expected offset and chars", lineNo)
+		}
+		offset, err := strconv.ParseFloat(fields[0], 64)
+		if err != nil {
+			return nil, fmt.Errorf("keyboard layout line %d: %v", lineNo, err)
+		}
+		rows = append(rows, Row{offset, strings.ToLower(fields[1])})
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	if name == "" {
+		return nil, fmt.Errorf("keyboard layout has no name")
+	}
+	if len(rows) == 0 {
+		return nil, fmt.Errorf("keyboard layout %q has no rows", name)
+	}
+	return NewRowsLayout(name, rows), nil
+}
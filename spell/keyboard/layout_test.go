@@ -0,0 +1,54 @@
+package keyboard
+
+import (
+	"math"
+	"strings"
+	"testing"
+)
+
+const eps = 1e-9
+
+func TestDistances(t *testing.T) {
+	cases := []struct {
+		layout   *Layout
+		from, to rune
+		distance float64
+	}{
+		{Qwerty, 'q', 'w', 1},
+		{Qwerty, 'q', 'a', 1.4},
+		{Qwerty, 'a', 'я', -1},
+		{Qwertz, 'z', 'u', 1},
+		{Azerty, 'a', 'z', 1},
+		{Dvorak, 'a', 'o', 1},
+		{Jcuken, 'й', 'ф', 1.4},
+	}
+	for _, c := range cases {
+		if distance := c.layout.Distance(c.from, c.to); math.Abs(distance-c.distance) > eps {
+			t.Errorf("%s: distance %c-%c is %v, expected %v", c.layout.Name, c.from, c.to, distance, c.distance)
+		}
+	}
+}
+
+func TestLayoutParserRoundTrip(t *testing.T) {
+	text := strings.Join([]string{
+		"# qwerty written out",
+		"name: qwerty-copy",
+		"-1 `1234567890-=",
+		"0.5 qwertyuiop[]",
+		"0.9 asdfghjkl;'",
+		"1.3 zxcvbnm,./",
+	}, "\n")
+	layout, err := NewLayoutParser().Parse(strings.NewReader(text))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if layout.Name != "qwerty-copy" || len(layout.Keys) != len(Qwerty.Keys) {
+		t.Fatalf("parsed layout %q has %d keys, expected %d", layout.Name, len(layout.Keys), len(Qwerty.Keys))
+	}
+	for _, key := range Qwerty.Keys {
+		location, ok := layout.Location(key.Char)
+		if !ok || math.Abs(location.X-key.Location.X) > eps || math.Abs(location.Y-key.Location.Y) > eps {
+			t.Errorf("%c is at %v, expected %v", key.Char, location, key.Location)
+		}
+	}
+}
@@ -0,0 +1,75 @@
+package keyboard
+
+import "sync"
+
+var (
+	offset0 = -1.0
+	offset1 = 0.5
+	offset2 = 0.9
+	offset3 = 1.3
+)
+
+// all built-in layouts share the same geometry, the digit 1 is at (0, 0)
+var (
+	Qwerty = NewRowsLayout("qwerty", []Row{
+		{offset0, "`1234567890-="},
+		{offset1, "qwertyuiop[]"},
+		{offset2, "asdfghjkl;'"},
+		{offset3, "zxcvbnm,./"},
+	})
+
+	Qwertz = NewRowsLayout("qwertz", []Row{
+		{offset0, "^1234567890ß´"},
+		{offset1, "qwertzuiopü+"},
+		{offset2, "asdfghjklöä#"},
+		{offset3, "yxcvbnm,.-"},
+	})
+
+	Azerty = NewRowsLayout("azerty", []Row{
+		{offset0, "²&é\"'(-è_çà)="},
+		{offset1, "azertyuiop^$"},
+		{offset2, "qsdfghjklmù*"},
+		{offset3, "wxcvbn,;:!"},
+	})
+
+	Dvorak = NewRowsLayout("dvorak", []Row{
+		{offset0, "`1234567890[]"},
+		{offset1, "',.pyfgcrl/="},
+		{offset2, "aoeuidhtns-"},
+		{offset3, ";qjkxbmwvz"},
+	})
+
+	Jcuken = NewRowsLayout("jcuken", []Row{
+		{offset0, "ё1234567890-="},
+		{offset1, "йцукенгшщзхъ"},
+		{offset2, "фывапролджэ"},
+		{offset3, "ячсмитьбю."},
+	})
+)
+
+var (
+	layouts = map[string]*Layout{
+		Qwerty.Name: Qwerty,
+		Qwertz.Name: Qwertz,
+		Azerty.Name: Azerty,
+		Dvorak.Name: Dvorak,
+		Jcuken.Name: Jcuken,
+	}
+	layoutsMutex sync.RWMutex
+)
+
+/**
+	Make a custom layout available by its name
+ */
+func Register(layout *Layout) {
+	layoutsMutex.Lock()
+	defer layoutsMutex.Unlock()
+	layouts[layout.Name] = layout
+}
+
+func LayoutByName(name string) (*Layout, bool) {
+	layoutsMutex.RLock()
+	defer layoutsMutex.RUnlock()
+	layout, ok := layouts[name]
+	return layout, ok
+}
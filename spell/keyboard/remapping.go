@@ -0,0 +1,59 @@
+package keyboard
+
+import (
+	"math"
+	"strings"
+)
+
+/**
+	Remapping translates text typed with one layout active into the text
+	the same keys produce with another layout, e.g. "ghbdtn" -> "привет"
+ */
+type Remapping struct {
+	From  string
+	To    string
+	Chars map[rune]rune
+}
+
+func NewRemapping(from, to *Layout) *Remapping {
+	var (
+		toChars = make(map[KeyLocation]rune, len(to.Keys))
+		chars   = make(map[rune]rune, len(from.Keys))
+	)
+	for _, key := range to.Keys {
+		toChars[roundLocation(key.Location)] = key.Char
+	}
+	for _, key := range from.Keys {
+		if char, ok := toChars[roundLocation(key.Location)]; ok {
+			chars[key.Char] = char
+		}
+	}
+	return &Remapping{
+		From:  from.Name,
+		To:    to.Name,
+		Chars: chars,
+	}
+}
+
+/**
+	Remap input, false if some char has no key on any of the layouts
+ */
+func (remapping *Remapping) Remap(input string) (string, bool) {
+	var builder strings.Builder
+	for _, char := range strings.ToLower(input) {
+		remapped, ok := remapping.Chars[char]
+		if !ok {
+			return "", false
+		}
+		builder.WriteRune(remapped)
+	}
+	return builder.String(), true
+}
+
+// layouts made of rows with the same offsets may still differ in the last float digits
+func roundLocation(location KeyLocation) KeyLocation {
+	return KeyLocation{
+		X: math.Round(location.X*100) / 100,
+		Y: math.Round(location.Y*100) / 100,
+	}
+}
@@ -0,0 +1,60 @@
+package spell
+
+import (
+	"encoding/json"
+	"io"
+	"sync"
+	"time"
+)
+
+/**
+	LearnEvent is reported by learners during learning, the last event of a learning has IsFinal set.
+	Fields a learner doesn't track are left zero
+ */
+type LearnEvent struct {
+	Learner       string        `json:"learner"`
+	Step          int           `json:"step"`
+	BestScore     float64       `json:"best_score"`
+	Loss          float64       `json:"loss"`
+	RelaxingCount int           `json:"relaxing_count"`
+	Elapsed       time.Duration `json:"elapsed_ns"`
+	Weights       []float64     `json:"weights,omitempty"`
+	IsFinal       bool          `json:"is_final"`
+}
+
+/**
+	LearnEventHandler is called synchronously from the learning goroutine
+ */
+type LearnEventHandler func(event LearnEvent)
+
+/**
+	LearnEventLogger writes events as JSON lines, its Log method is a LearnEventHandler
+ */
+type LearnEventLogger struct {
+	encoder *json.Encoder
+	mutex   sync.Mutex
+	err     error
+}
+
+func NewLearnEventLogger(w io.Writer) *LearnEventLogger {
+	return &LearnEventLogger{
+		encoder: json.NewEncoder(w),
+	}
+}
+
+func (logger *LearnEventLogger) Log(event LearnEvent) {
+	logger.mutex.Lock()
+	defer logger.mutex.Unlock()
+	if err := logger.encoder.Encode(event); err != nil && logger.err == nil {
+		logger.err = err
+	}
+}
+
+/**
+	The first write error, if any
+ */
+func (logger *LearnEventLogger) Err() error {
+	logger.mutex.Lock()
+	defer logger.mutex.Unlock()
+	return logger.err
+}
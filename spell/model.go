@@ -1,18 +1,28 @@
 package spell
 
 import (
+	"context"
+	"math"
 	"regexp"
-	"runtime"
 	"sort"
+	"spell/keyboard"
 	"strings"
 	"sync"
 	"unicode/utf8"
 )
 
+/**
+	Version of the model binary format, models of other versions must be retrained.
+	Version 2 indexes Affects by rune lengths of edits instead of byte lengths
+ */
+const ModelFormatVersion = 2
+
 /**
 	Model represents misspell corrector structure
  */
 type Model struct {
+	FormatVersion int
+
 	Terms     []string
 	TermsDict map[string]int
 	Index     map[string][][]int
@@ -26,14 +36,14 @@ type Model struct {
 	TermsCounts []float64
 	TotalTerms  float64
 
-	distanceMeasurers  []*DistanceMeasurer `binary:"-"`
-	currentMeasurerId  int                 `binary:"-"`
-	measurersSemaphore chan bool           `binary:"-"`
-	measurersMutex     sync.Mutex          `binary:"-"`
+	measurers        sync.Pool              `binary:"-"`
+	layoutRemappings []*keyboard.Remapping `binary:"-"`
 }
 
 func InitModel() *Model {
 	model := Model{
+		FormatVersion: ModelFormatVersion,
+
 		Terms:         []string{},
 		TermsDict:     map[string]int{},
 		Index:         map[string][][]int{},
@@ -52,15 +62,57 @@ func InitModel() *Model {
 	return &model
 }
 
+/**
+	Each lookup takes its own measurer from the pool and keeps it for the whole lookup,
+	so concurrent lookups never share measurer matrices
+ */
 func (model *Model) InitMeasurers()  {
-	procCount := runtime.GOMAXPROCS(-1)
-	model.distanceMeasurers = make([]*DistanceMeasurer, procCount)
-	for i := 0; i < procCount; i++ {
-		model.distanceMeasurers[i] = NewDistanceMeasurer()
-	}
-	model.currentMeasurerId = 0
-	model.measurersSemaphore = make(chan bool, procCount)
-	model.measurersMutex = sync.Mutex{}
+	model.measurers.New = func() interface{} {
+		return NewDistanceMeasurer()
+	}
+}
+
+func (model *Model) acquireMeasurer() *DistanceMeasurer {
+	if measurer, ok := model.measurers.Get().(*DistanceMeasurer); ok {
+		return measurer
+	}
+	return NewDistanceMeasurer()
+}
+
+func (model *Model) releaseMeasurer(measurer *DistanceMeasurer) {
+	model.measurers.Put(measurer)
+}
+
+/**
+	Look for terms typed with a wrong layout active in both directions between the layouts.
+	Pairs should be added before the model is used for lookups
+ */
+func (model *Model) AddLayoutPair(a, b *keyboard.Layout) {
+	model.layoutRemappings = append(model.layoutRemappings, keyboard.NewRemapping(a, b), keyboard.NewRemapping(b, a))
+}
+
+/**
+	Remap input through the configured layout pairs and return the known terms
+ */
+func (model *Model) GetLayoutFixes(input string) []Suggestion {
+	input = strings.ToLower(input)
+	fixes := make([]Suggestion, 0)
+	for _, remapping := range model.layoutRemappings {
+		term, ok := remapping.Remap(input)
+		if !ok || term == input {
+			continue
+		}
+		if termIndex, ok := model.TermsDict[term]; ok {
+			fixes = append(fixes, Suggestion{
+				Term:        term,
+				Distance:    0,
+				Score:       0,
+				Count:       model.TermsCounts[termIndex],
+				IsLayoutFix: true,
+			})
+		}
+	}
+	return fixes
 }
 
 /**
@@ -89,8 +141,14 @@ func (model *Model) TrainText(text []byte) {
 		terms[term] += 1
 	}
 
-	for term, count := range terms {
-		if count >= DefaultMinTermCount {
+	// terms ids don't depend on map order, so the same text gives the same model
+	sortedTerms := make([]string, 0, len(terms))
+	for term := range terms {
+		sortedTerms = append(sortedTerms, term)
+	}
+	sort.Strings(sortedTerms)
+	for _, term := range sortedTerms {
+		if count := terms[term]; count >= DefaultMinTermCount {
 			model.AddTerm(term, count)
 		}
 	}
@@ -179,7 +237,7 @@ func (model *Model) AddTerm(term string, count float64) bool {
 	if !model.KnownAffects[termI] {
 		trackingMultiEdits := GetTrackingMultiEdits(termLo, OperationAffectedChange{0, map[int]bool{}}, float64(model.Depth))
 		for edit, trackingEdit := range trackingMultiEdits {
-			editLen := len(edit)
+			editLen := utf8.RuneCountInString(edit)
 			for inputDiff := range trackingEdit.InputLens {
 				inputLen := termLen + inputDiff
 				if inputLen > len(model.Affects) {
@@ -220,7 +278,23 @@ func (model *Model) AddTerm(term string, count float64) bool {
 	Calculate raw unsorted suggestions
  */
 func (model *Model) GetRawSuggestions(input string, calcEditorialPrescription bool) map[string]Suggestion {
-	result := make(map[string]Suggestion)
+	result, _ := model.GetRawSuggestionsContext(context.Background(), input, calcEditorialPrescription)
+	return result
+}
+
+/**
+	Calculate raw unsorted suggestions while ctx is alive.
+	When ctx is cancelled or its deadline is exceeded the suggestions found so far are returned and isPartial is set,
+	edits generated before that are looked up until at least one candidate is found
+ */
+func (model *Model) GetRawSuggestionsContext(ctx context.Context, input string, calcEditorialPrescription bool) (result map[string]Suggestion, isPartial bool) {
+	result, isPartial = model.collectSuggestions(ctx, input, calcEditorialPrescription)
+	model.setFrequencies(input, result)
+	return
+}
+
+func (model *Model) collectSuggestions(ctx context.Context, input string, calcEditorialPrescription bool) (result map[string]Suggestion, isPartial bool) {
+	result = make(map[string]Suggestion)
 	input = strings.ToLower(input)
 	var (
 		termsByLen   [][]int
@@ -245,17 +319,33 @@ func (model *Model) GetRawSuggestions(input string, calcEditorialPrescription bo
 		}
 	}
 
+	for _, fix := range model.GetLayoutFixes(input) {
+		if _, ok := result[fix.Term]; !ok {
+			result[fix.Term] = fix
+		}
+	}
+
 	// Index doesn't have any term that can be potentially mathed to input
 	if inputLen > len(model.Affects) {
-		return result
+		return
 	}
 	inputAffects = model.Affects[inputLen-1]
 	if inputAffects == nil {
-		return result
+		return
 	}
 
-	edits := GetMultiEdits(input, 0.0, float64(model.Depth))
+	measurer := model.acquireMeasurer()
+	defer model.releaseMeasurer(measurer)
+
+	// edits generated before the deadline are still looked up until the first candidate is found,
+	// otherwise a deadline hit during edits generation would leave nothing but the exact match
+	edits, isPartial := GetMultiEditsContext(ctx, input, 0.0, float64(model.Depth))
+	isFound := false
 	for edit := range edits {
+		if isFound && isDone(ctx) {
+			isPartial = true
+			break
+		}
 		editHead, editTail := model.splitEdit(edit)
 
 		if termsByLen, ok = model.Index[editHead]; !ok {
@@ -287,16 +377,7 @@ func (model *Model) GetRawSuggestions(input string, calcEditorialPrescription bo
 			for _, termIndex := range termsIndex {
 				term = model.Terms[termIndex]
 
-				model.measurersSemaphore <- true
-				model.measurersMutex.Lock()
-				model.currentMeasurerId++
-				if model.currentMeasurerId == len(model.distanceMeasurers) {
-					model.currentMeasurerId = 0
-				}
-				measurer := model.distanceMeasurers[model.currentMeasurerId]
-				model.measurersMutex.Unlock()
-				distance, editorialPrescription := measurer.Distance(term, input, calcEditorialPrescription)
-				<- model.measurersSemaphore
+				distance, editorialPrescription := measurer.BoundedDistance(term, input, model.Depth, calcEditorialPrescription)
 
 				if distance > model.Depth {
 					continue
@@ -309,12 +390,92 @@ func (model *Model) GetRawSuggestions(input string, calcEditorialPrescription bo
 						Score:        0,
 						Count:    model.TermsCounts[termIndex],
 					}
+					isFound = true
 				}
 			}
 		}
 	}
 
-	return result
+	return
+}
+
+/**
+	Frequencies are known only when all the candidates are, so they are set after the lookup
+ */
+func (model *Model) setFrequencies(input string, suggestions map[string]Suggestion) {
+	terms := make([]string, 0, len(suggestions))
+	list := make([]Suggestion, 0, len(suggestions))
+	for term, suggestion := range suggestions {
+		terms = append(terms, term)
+		list = append(list, suggestion)
+	}
+	model.SetFrequencies(input, list)
+	for i, term := range terms {
+		suggestions[term] = list[i]
+	}
+}
+
+/**
+	Set Frequency and FrequencyRank of the suggestions for the input. Ranks are taken among the corrections:
+	the input itself and layout fixes aren't ranked against, so learning terms and lookups rank alike.
+	Suggestions read from a cache must be set again
+ */
+func (model *Model) SetFrequencies(input string, suggestions []Suggestion) {
+	counts := make([]float64, 0, len(suggestions))
+	for _, suggestion := range suggestions {
+		if isCorrection(input, &suggestion) {
+			counts = append(counts, suggestion.Count)
+		}
+	}
+	sort.Sort(sort.Reverse(sort.Float64Slice(counts)))
+
+	for i := range suggestions {
+		suggestion := &suggestions[i]
+		if model.TotalTerms > 0 {
+			suggestion.Frequency = suggestion.Count / model.TotalTerms
+		}
+		suggestion.FrequencyRank = 0
+		if len(counts) > 1 {
+			// equal counts share the rank of the first of them
+			rank := sort.Search(len(counts), func(i int) bool {
+				return counts[i] <= suggestion.Count
+			})
+			suggestion.FrequencyRank = math.Min(float64(rank)/float64(len(counts)-1), 1)
+		}
+	}
+}
+
+func isCorrection(input string, suggestion *Suggestion) bool {
+	return suggestion.Term != input && !suggestion.IsLayoutFix
+}
+
+/**
+	Learning term of the misspell with the corrections found for it, nil if there are none or the term is unknown.
+	Corrections are sorted by term, learners must get them in the same order every run
+ */
+func (model *Model) GetLearningTerm(term, misspell string) *LearningTerm {
+	term = strings.ToLower(term)
+	if !model.HasTerm(term) {
+		return nil
+	}
+	misspell = strings.ToLower(misspell)
+	suggestions := make([]Suggestion, 0)
+	for _, suggestion := range model.GetRawSuggestions(misspell, true) {
+		if isCorrection(misspell, &suggestion) && suggestion.Prescription != nil {
+			suggestions = append(suggestions, suggestion)
+		}
+	}
+	if len(suggestions) == 0 {
+		return nil
+	}
+	sort.Slice(suggestions, func(i, j int) bool {
+		return suggestions[i].Term < suggestions[j].Term
+	})
+	return &LearningTerm{
+		Term:        term,
+		Misspell:    misspell,
+		Suggestions: suggestions,
+	}
 }
 
 /**
@@ -322,15 +483,75 @@ func (model *Model) GetRawSuggestions(input string, calcEditorialPrescription bo
 */
 func (model *Model) GetSuggestions(input string, scoreModel ScoreModel, calcEditorialPrescription bool) []Suggestion  {
 	var rawSuggestions = model.GetRawSuggestions(input, calcEditorialPrescription)
-	suggestions := make([]Suggestion, 0, len(rawSuggestions))
+	return model.sortSuggestions(rawSuggestions, scoreModel)
+}
+
+/**
+	Return suggestions sorted by given scorer while ctx is alive.
+	When ctx is done the best of the suggestions found so far are returned and isPartial is set
+*/
+func (model *Model) GetSuggestionsContext(ctx context.Context, input string, scoreModel ScoreModel, calcEditorialPrescription bool) (suggestions []Suggestion, isPartial bool) {
+	rawSuggestions, isPartial := model.GetRawSuggestionsContext(ctx, input, calcEditorialPrescription)
+	return model.sortSuggestions(rawSuggestions, scoreModel), isPartial
+}
+
+/**
+	Layout fixes have no prescription and scorers would take them for exact matches, so they aren't scored.
+	They are put right after the corrections within LayoutFixDistance edits, the more frequent fix first
+ */
+func (model *Model) sortSuggestions(rawSuggestions map[string]Suggestion, scoreModel ScoreModel) []Suggestion {
+	var (
+		suggestions = make([]Suggestion, 0, len(rawSuggestions))
+		fixes       = make([]Suggestion, 0)
+	)
 	for _, suggestion := range rawSuggestions {
-		suggestion.Score = scoreModel.Score(&suggestion)
-		suggestions = append(suggestions, suggestion)
+		if suggestion.IsLayoutFix {
+			fixes = append(fixes, suggestion)
+		} else {
+			suggestions = append(suggestions, suggestion)
+		}
 	}
-	sort.Slice(suggestions, func(i, j int) bool {
-		return suggestions[i].Score < suggestions[j].Score
+	ScoreSuggestions(scoreModel, suggestions)
+	// raw suggestions come from a map, equal scores are ordered by term to rank them the same every run
+	sort.SliceStable(suggestions, func(i, j int) bool {
+		if suggestions[i].Score != suggestions[j].Score {
+			return suggestions[i].Score < suggestions[j].Score
+		}
+		return suggestions[i].Term < suggestions[j].Term
 	})
-	return suggestions
+	if len(fixes) == 0 {
+		return suggestions
+	}
+
+	sort.Slice(fixes, func(i, j int) bool {
+		if fixes[i].Count != fixes[j].Count {
+			return fixes[i].Count > fixes[j].Count
+		}
+		return fixes[i].Term < fixes[j].Term
+	})
+	position := 0
+	for i, suggestion := range suggestions {
+		if suggestion.Distance <= LayoutFixDistance {
+			position = i + 1
+		}
+	}
+	result := make([]Suggestion, 0, len(suggestions)+len(fixes))
+	result = append(result, suggestions[:position]...)
+	result = append(result, fixes...)
+	return append(result, suggestions[position:]...)
+}
+
+/**
+	Set Score of every suggestion, batch score models get all the suggestions at once
+ */
+func ScoreSuggestions(scoreModel ScoreModel, suggestions []Suggestion) {
+	if batchScoreModel, ok := scoreModel.(BatchScoreModel); ok {
+		batchScoreModel.ScoreAll(suggestions)
+		return
+	}
+	for i := range suggestions {
+		suggestions[i].Score = scoreModel.Score(&suggestions[i])
+	}
 }
 
 func (model *Model) splitEdit(edit string) (string, string) {
@@ -350,20 +571,44 @@ func (model *Model) splitEdit(edit string) (string, string) {
 }
 
 func GetMultiEdits(term string, usedWeight float64, maxWeight float64) map[string]float64 {
-	edits := GetEdits(term, usedWeight, maxWeight)
+	edits, _ := GetMultiEditsContext(context.Background(), term, usedWeight, maxWeight)
+	return edits
+}
+
+/**
+	Same as GetMultiEdits but stops traversal as soon as ctx is done.
+	In that case the edits collected so far are returned and isPartial is set
+ */
+func GetMultiEditsContext(ctx context.Context, term string, usedWeight float64, maxWeight float64) (edits map[string]float64, isPartial bool) {
+	edits = GetEdits(term, usedWeight, maxWeight)
 	if usedWeight < maxWeight {
 		traversalEdits := make(map[string]float64)
 		for k, v := range edits {
 			traversalEdits[k] = v
 		}
 		for term, weight := range traversalEdits {
-			subedits := GetMultiEdits(term, usedWeight+weight, maxWeight)
+			if isDone(ctx) {
+				return edits, true
+			}
+			subedits, isSubPartial := GetMultiEditsContext(ctx, term, usedWeight+weight, maxWeight)
 			for subterm, v := range subedits {
 				edits[subterm] = v
 			}
+			if isSubPartial {
+				return edits, true
+			}
 		}
 	}
-	return edits
+	return
+}
+
+func isDone(ctx context.Context) bool {
+	select {
+	case <-ctx.Done():
+		return true
+	default:
+		return false
+	}
 }
 
 func GetEdits(term string, usedWeight float64, maxWeight float64) map[string]float64 {
@@ -0,0 +1,135 @@
+package spell
+
+import (
+	"compress/gzip"
+	"context"
+	"os"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func trainedModel() *Model {
+	model := InitModel()
+	model.TrainTerms([]string{"hello", "help", "world", "would", "word", "spelling", "spell", "speller"})
+	return model
+}
+
+func TestGetRawSuggestionsContextExpiredDeadline(t *testing.T) {
+	model := trainedModel()
+	ctx, cancel := context.WithDeadline(context.Background(), time.Now())
+	defer cancel()
+	<-ctx.Done()
+
+	suggestions, isPartial := model.GetRawSuggestionsContext(ctx, "helo", true)
+	if !isPartial {
+		t.Error("isPartial isn't set for an expired deadline")
+	}
+	if len(suggestions) == 0 {
+		t.Error("no candidates are found for an expired deadline")
+	}
+	for term, suggestion := range suggestions {
+		if suggestion.Distance > model.Depth {
+			t.Errorf("%s is at distance %d", term, suggestion.Distance)
+		}
+	}
+}
+
+func BenchmarkGetRawSuggestionsParallel(b *testing.B) {
+	misspells, err := benchmarkMisspells("cmd/data/misspells.txt.gz")
+	if err != nil {
+		b.Skip(err)
+	}
+	var (
+		model  = InitModel()
+		inputs []string
+	)
+	for _, misspell := range misspells {
+		model.AddTerm(strings.ToLower(misspell.Term), 1)
+		for _, input := range misspell.Misspells {
+			inputs = append(inputs, strings.ToLower(input))
+		}
+	}
+	b.ResetTimer()
+	b.RunParallel(func(pb *testing.PB) {
+		i := 0
+		for pb.Next() {
+			model.GetRawSuggestions(inputs[i%len(inputs)], true)
+			i++
+		}
+	})
+}
+
+func benchmarkMisspells(fileName string) ([]Misspell, error) {
+	file, err := os.Open(fileName)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+	reader, err := gzip.NewReader(file)
+	if err != nil {
+		return nil, err
+	}
+	return NewMisspellParser().Parse(reader)
+}
+
+func TestSetFrequencies(t *testing.T) {
+	model := trainedModel()
+	model.TotalTerms = 10
+	suggestions := []Suggestion{
+		{Term: "help", Count: 2},
+		{Term: "hello", Count: 5},
+		{Term: "held", Count: 2},
+		{Term: "hell", Count: 1},
+	}
+	model.SetFrequencies("", suggestions)
+	expected := []float64{1.0 / 3, 0, 1.0 / 3, 1}
+	for i, suggestion := range suggestions {
+		if suggestion.FrequencyRank != expected[i] {
+			t.Errorf("%s has rank %v, expected %v", suggestion.Term, suggestion.FrequencyRank, expected[i])
+		}
+		if suggestion.Frequency != suggestion.Count/10 {
+			t.Errorf("%s has frequency %v, expected %v", suggestion.Term, suggestion.Frequency, suggestion.Count/10)
+		}
+	}
+}
+
+func TestGetRawSuggestionsOfCyrillicTerm(t *testing.T) {
+	model := InitModel()
+	model.TrainTerms([]string{"привет", "пирог"})
+	for _, input := range []string{"привт", "пирвет", "прывет"} {
+		if _, ok := model.GetRawSuggestions(input, true)["привет"]; !ok {
+			t.Errorf("привет isn't found for %s", input)
+		}
+	}
+}
+
+func TestLearningTermsAndLookupsRankFrequenciesAlike(t *testing.T) {
+	model := InitModel()
+	for term, count := range map[string]float64{"hello": 5, "help": 2, "held": 3, "hell": 1, "helo": 9} {
+		model.AddTerm(term, count)
+	}
+	learningTerm := model.GetLearningTerm("hello", "helo")
+	if learningTerm == nil {
+		t.Fatal("no learning term for helo")
+	}
+	lookup := model.GetRawSuggestions("helo", true)
+	for _, suggestion := range learningTerm.Suggestions {
+		if suggestion.FrequencyRank != lookup[suggestion.Term].FrequencyRank {
+			t.Errorf("%s has rank %v in the learning term and %v in the lookup", suggestion.Term, suggestion.FrequencyRank, lookup[suggestion.Term].FrequencyRank)
+		}
+	}
+	// cached learning terms are set again
+	suggestions := append([]Suggestion(nil), learningTerm.Suggestions...)
+	for i := range suggestions {
+		suggestions[i].FrequencyRank = 0.5
+	}
+	model.SetFrequencies(learningTerm.Misspell, suggestions)
+	if !reflect.DeepEqual(suggestions, learningTerm.Suggestions) {
+		t.Errorf("ranks set again are %v, expected %v", suggestions, learningTerm.Suggestions)
+	}
+	if lookup["hello"].FrequencyRank != 0 {
+		t.Errorf("the most frequent correction has rank %v, the input mustn't be ranked against", lookup["hello"].FrequencyRank)
+	}
+}
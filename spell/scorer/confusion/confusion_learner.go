@@ -0,0 +1,117 @@
+package confusion
+
+import (
+	"fmt"
+	"spell"
+	"time"
+)
+
+type LearnProgress struct {
+	ProcessedTerms int
+}
+
+/**
+	Learner counts confusions over the alignments of learning terms and their misspells
+ */
+type Learner struct {
+	Smoothing      float64
+	CountSmoothing float64
+	OnEvent        spell.LearnEventHandler
+	learnProgress  LearnProgress
+}
+
+func InitLearner() *Learner {
+	return &Learner{
+		Smoothing:      0.5,
+		CountSmoothing: 1,
+	}
+}
+
+func (learner *Learner) Learn(learningData []*spell.LearningTerm) spell.ScoreModel {
+	learner.learnProgress = LearnProgress{}
+	startedAt := time.Now()
+	var (
+		matrices = InitMatrices()
+		measurer = spell.NewDistanceMeasurer()
+		alphabet = map[rune]bool{}
+	)
+	for _, learningTerm := range learningData {
+		prescription := learningPrescription(learningTerm)
+		if prescription == nil {
+			_, prescription = measurer.Distance(learningTerm.Term, learningTerm.Misspell, true)
+		}
+		learner.countEdits(matrices, prescription)
+		learner.countChars(matrices, learningTerm.Term)
+		for _, char := range learningTerm.Term {
+			alphabet[char] = true
+		}
+		learner.learnProgress.ProcessedTerms++
+	}
+	if learner.OnEvent != nil {
+		learner.OnEvent(spell.LearnEvent{
+			Learner: "confusion",
+			Step:    learner.learnProgress.ProcessedTerms,
+			Elapsed: time.Since(startedAt),
+			IsFinal: true,
+		})
+	}
+	return &Scorer{
+		Matrices:       matrices,
+		Smoothing:      learner.Smoothing,
+		AlphabetSize:   float64(len(alphabet) + 1),
+		CountSmoothing: learner.CountSmoothing,
+	}
+}
+
+func (learner *Learner) LearnProgress() string {
+	learnProgress := learner.learnProgress
+	return fmt.Sprintf("Processed terms: %d", learnProgress.ProcessedTerms)
+}
+
+func (learner *Learner) countEdits(matrices *Matrices, prescription *spell.EditorialPrescription) {
+	for _, edit := range prescription.Edits() {
+		var (
+			from = edit.Froms[0]
+			to   = edit.Tos[0]
+			prev = previousTermChar(prescription, edit.Steps[0])
+		)
+		switch edit.Action {
+		case spell.Replace:
+			matrices.Substitutions[string([]rune{from, to})]++
+		case spell.Insert:
+			matrices.Insertions[string([]rune{prev, to})]++
+		case spell.Duplicate:
+			matrices.Insertions[string([]rune{to, to})]++
+		case spell.Delete:
+			matrices.Deletions[string([]rune{prev, from})]++
+		case spell.MissDouble:
+			matrices.Deletions[string([]rune{from, from})]++
+		case spell.Transposition:
+			if len(edit.Froms) == 2 {
+				matrices.Transpositions[string(edit.Froms)]++
+			}
+		case spell.Triplet:
+			matrices.Triplets[string(edit.Froms)]++
+		}
+	}
+}
+
+// unigrams, bigrams and trigrams of the term, bigrams include the leading word boundary
+func (learner *Learner) countChars(matrices *Matrices, term string) {
+	termR := append([]rune{WordBoundary}, []rune(term)...)
+	for i := range termR {
+		for n := 1; n <= 3 && i+n <= len(termR); n++ {
+			matrices.Chars[string(termR[i:i+n])]++
+		}
+	}
+}
+
+// prescription of the correct suggestion
+func learningPrescription(learningTerm *spell.LearningTerm) *spell.EditorialPrescription {
+	for _, suggestion := range learningTerm.Suggestions {
+		if suggestion.Term == learningTerm.Term {
+			return suggestion.Prescription
+		}
+	}
+	return nil
+}
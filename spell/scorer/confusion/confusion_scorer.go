@@ -0,0 +1,170 @@
+package confusion
+
+import (
+	"fmt"
+	"math"
+	"spell"
+)
+
+/**
+	WordBoundary is the context char of edits at the term start
+ */
+const WordBoundary = '^'
+
+/**
+	Matrices are per char confusion counts in the manner of Kernighan, Church and Gale:
+	Substitutions["xy"] - x typed as y,
+	Insertions["xy"] - y typed after x, Deletions["xy"] - y after x is missed,
+	Transpositions["xy"] - xy typed as yx, Triplets["xyz"] - xyz mixed up.
+	Chars holds counts of term unigrams, bigrams and trigrams used as denominators
+ */
+type Matrices struct {
+	Substitutions  map[string]float64
+	Insertions     map[string]float64
+	Deletions      map[string]float64
+	Transpositions map[string]float64
+	Triplets       map[string]float64
+	Chars          map[string]float64
+}
+
+func InitMatrices() *Matrices {
+	return &Matrices{
+		Substitutions:  map[string]float64{},
+		Insertions:     map[string]float64{},
+		Deletions:      map[string]float64{},
+		Transpositions: map[string]float64{},
+		Triplets:       map[string]float64{},
+		Chars:          map[string]float64{},
+	}
+}
+
+/**
+	Scorer ranks candidates by P(misspell|term)·P(term), P(term) is taken from Suggestion.Count
+ */
+type Scorer struct {
+	*Matrices
+	Smoothing      float64 // added to every confusion count
+	AlphabetSize   float64
+	CountSmoothing float64 // added to Suggestion.Count
+}
+
+func (scoring *Scorer) Compare(a *spell.Suggestion, b *spell.Suggestion) float64 {
+	a.Score = scoring.Score(a)
+	b.Score = scoring.Score(b)
+	return a.Score - b.Score
+}
+
+/**
+	Negative log probability, the lower the better
+ */
+func (scoring *Scorer) Score(a *spell.Suggestion) float64 {
+	return -(scoring.LogErrorProbability(a.Prescription) + math.Log(a.Count+scoring.CountSmoothing))
+}
+
+/**
+	log P(misspell|term), zero for exact matches
+ */
+func (scoring *Scorer) LogErrorProbability(prescription *spell.EditorialPrescription) float64 {
+	if prescription == nil {
+		return 0
+	}
+	logProbability := 0.0
+	for _, edit := range prescription.Edits() {
+		logProbability += scoring.logEditProbability(prescription, edit)
+	}
+	return logProbability
+}
+
+/**
+	A contribution per edit and one of the term count, they sum up to Score
+ */
+func (scoring *Scorer) ExplainScore(a *spell.Suggestion) []spell.FeatureContribution {
+	logCount := math.Log(a.Count + scoring.CountSmoothing)
+	contributions := []spell.FeatureContribution{{
+		Name:         "log-count",
+		Value:        logCount,
+		Weight:       -1,
+		Contribution: -logCount,
+	}}
+	if a.Prescription == nil {
+		return contributions
+	}
+	for _, edit := range a.Prescription.Edits() {
+		if edit.Action == spell.Match {
+			continue
+		}
+		logProbability := scoring.logEditProbability(a.Prescription, edit)
+		contributions = append(contributions, spell.FeatureContribution{
+			Name:         editName(a.Prescription, edit),
+			Value:        1,
+			Weight:       -logProbability,
+			Contribution: -logProbability,
+		})
+	}
+	return contributions
+}
+
+/**
+	Edit with its confusion matrix context, e.g. "replace(e→a)" or "delete(e after r)"
+ */
+func editName(prescription *spell.EditorialPrescription, edit spell.Edit) string {
+	var (
+		from = edit.Froms[0]
+		to   = edit.Tos[0]
+		prev = previousTermChar(prescription, edit.Steps[0])
+	)
+	switch edit.Action {
+	case spell.Replace:
+		return fmt.Sprintf("%s(%c→%c)", edit.Action, from, to)
+	case spell.Insert:
+		return fmt.Sprintf("%s(%c after %c)", edit.Action, to, prev)
+	case spell.Delete:
+		return fmt.Sprintf("%s(%c after %c)", edit.Action, from, prev)
+	case spell.Duplicate:
+		return fmt.Sprintf("%s(%c)", edit.Action, to)
+	case spell.MissDouble:
+		return fmt.Sprintf("%s(%c)", edit.Action, from)
+	}
+	return fmt.Sprintf("%s(%s)", edit.Action, string(edit.Froms))
+}
+
+func (scoring *Scorer) logEditProbability(prescription *spell.EditorialPrescription, edit spell.Edit) float64 {
+	var (
+		from = edit.Froms[0]
+		to   = edit.Tos[0]
+		prev = previousTermChar(prescription, edit.Steps[0])
+	)
+	switch edit.Action {
+	case spell.Replace:
+		return scoring.logProbability(scoring.Substitutions, string([]rune{from, to}), string(from))
+	case spell.Insert:
+		return scoring.logProbability(scoring.Insertions, string([]rune{prev, to}), string(prev))
+	case spell.Duplicate:
+		return scoring.logProbability(scoring.Insertions, string([]rune{to, to}), string(to))
+	case spell.Delete:
+		return scoring.logProbability(scoring.Deletions, string([]rune{prev, from}), string([]rune{prev, from}))
+	case spell.MissDouble:
+		return scoring.logProbability(scoring.Deletions, string([]rune{from, from}), string([]rune{from, from}))
+	case spell.Transposition:
+		if len(edit.Froms) == 2 {
+			return scoring.logProbability(scoring.Transpositions, string(edit.Froms), string(edit.Froms))
+		}
+	case spell.Triplet:
+		return scoring.logProbability(scoring.Triplets, string(edit.Froms), string(edit.Froms))
+	}
+	return 0
+}
+
+func (scoring *Scorer) logProbability(confusions map[string]float64, key, context string) float64 {
+	return math.Log((confusions[key] + scoring.Smoothing) / (scoring.Chars[context] + scoring.Smoothing*scoring.AlphabetSize))
+}
+
+// term char before the step, WordBoundary at the term start
+func previousTermChar(prescription *spell.EditorialPrescription, step int) rune {
+	for k := step - 1; k >= 0; k-- {
+		if prescription.Froms[k] != 0 {
+			return prescription.Froms[k]
+		}
+	}
+	return WordBoundary
+}
@@ -0,0 +1,52 @@
+package confusion
+
+import (
+	"spell"
+	"testing"
+)
+
+func suggestion(term, input string, count float64) *spell.Suggestion {
+	_, prescription := spell.NewDistanceMeasurer().Distance(term, input, true)
+	return &spell.Suggestion{Term: term, Count: count, Prescription: prescription}
+}
+
+func TestScoreOrder(t *testing.T) {
+	// e is typed as a
+	var learningData []*spell.LearningTerm
+	for _, pair := range [][2]string{{"bed", "bad"}, {"set", "sat"}, {"pen", "pan"}, {"bid", "bed"}} {
+		learningData = append(learningData, &spell.LearningTerm{Term: pair[0], Misspell: pair[1]})
+	}
+	scoring := InitLearner().Learn(learningData).(*Scorer)
+
+	cases := []struct {
+		name          string
+		better, worse *spell.Suggestion
+	}{
+		{"frequent confusion", suggestion("ted", "tad", 1), suggestion("tid", "tad", 1)},
+		{"frequent term", suggestion("ted", "tad", 10), suggestion("ted", "tad", 1)},
+		{"fewer edits", suggestion("ted", "tad", 1), suggestion("tod", "tab", 1)},
+		{"exact match", suggestion("tad", "tad", 1), suggestion("ted", "tad", 1)},
+	}
+	for _, c := range cases {
+		if scoring.Compare(c.better, c.worse) >= 0 {
+			t.Errorf("%s: %s scores %v, %s scores %v", c.name, c.better.Term, c.better.Score, c.worse.Term, c.worse.Score)
+		}
+	}
+}
+
+func TestExplainScoreSumsUpToScore(t *testing.T) {
+	scoring := InitLearner().Learn([]*spell.LearningTerm{{Term: "bed", Misspell: "bad"}}).(*Scorer)
+	for _, a := range []*spell.Suggestion{suggestion("ted", "tad", 3), suggestion("tide", "tad", 1), suggestion("tad", "tad", 2)} {
+		sum := 0.0
+		for _, contribution := range scoring.ExplainScore(a) {
+			sum += contribution.Contribution
+		}
+		if score := scoring.Score(a); !isClose(sum, score) {
+			t.Errorf("%s: contributions sum up to %v, score is %v", a.Term, sum, score)
+		}
+	}
+}
+
+func isClose(a, b float64) bool {
+	return a-b < 1e-9 && b-a < 1e-9
+}
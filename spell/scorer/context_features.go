@@ -0,0 +1,113 @@
+package scorer
+
+import (
+	"hash/fnv"
+	"spell"
+	"strings"
+	"unicode"
+)
+
+const (
+	termStart = '^'
+	termEnd   = '$'
+
+	DefaultContextBuckets = 64
+)
+
+var ContextFeatures = []string{
+	"edit-context",
+}
+
+func init() {
+	mustRegisterFeature(NewContextFeature("edit-context", DefaultContextBuckets))
+}
+
+/**
+	NewContextFeature hashes the chars around every edit into buckets slots. An edit gives tokens of
+	the edited chars, of the term chars next to it, of their vowel/consonant classes and of the edited char
+	doubling a neighbour, each token adds 1 to its bucket
+ */
+func NewContextFeature(name string, buckets int) *Feature {
+	return &Feature{
+		Name: name,
+		Size: buckets,
+		Extract: func(vectoriser *Vectoriser, suggestion *Extraction, xs []float64) {
+			for _, edit := range suggestion.Edits() {
+				for _, token := range contextTokens(suggestion.Prescription, edit) {
+					xs[hashToken(token)%uint32(len(xs))] += 1
+				}
+			}
+		},
+	}
+}
+
+func contextTokens(prescription *spell.EditorialPrescription, edit spell.Edit) []string {
+	var (
+		action = edit.Action.String()
+		from   = edit.Froms[0]
+		to     = edit.Tos[0]
+		left   = termCharBefore(prescription, edit.Steps[0])
+		right  = termCharAfter(prescription, edit.Steps[len(edit.Steps)-1])
+		edited = from
+	)
+	if edited == 0 {
+		edited = to
+	}
+	tokens := []string{
+		join(action, "from", charToken(from), "to", charToken(to)),
+		join(action, charToken(edited), "left", charToken(left)),
+		join(action, charToken(edited), "right", charToken(right)),
+		join(action, "classes", charClass(left), charClass(edited), charClass(right)),
+	}
+	if edited == left || edited == right {
+		tokens = append(tokens, join(action, "double", charToken(edited)))
+	}
+	return tokens
+}
+
+func termCharBefore(prescription *spell.EditorialPrescription, step int) rune {
+	for k := step - 1; k >= 0; k-- {
+		if prescription.Froms[k] != 0 {
+			return prescription.Froms[k]
+		}
+	}
+	return termStart
+}
+
+func termCharAfter(prescription *spell.EditorialPrescription, step int) rune {
+	for k := step + 1; k < len(prescription.Froms); k++ {
+		if prescription.Froms[k] != 0 {
+			return prescription.Froms[k]
+		}
+	}
+	return termEnd
+}
+
+func charClass(char rune) string {
+	switch true {
+	case char == termStart || char == termEnd || char == 0:
+		return "boundary"
+	case strings.ContainsRune("aeiouyаеёиоуыэюя", unicode.ToLower(char)):
+		return "vowel"
+	case unicode.IsLetter(char):
+		return "consonant"
+	}
+	return "other"
+}
+
+func charToken(char rune) string {
+	if char == 0 {
+		return ""
+	}
+	return string(char)
+}
+
+func join(parts ...string) string {
+	return strings.Join(parts, "\x00")
+}
+
+func hashToken(token string) uint32 {
+	hash := fnv.New32a()
+	hash.Write([]byte(token))
+	return hash.Sum32()
+}
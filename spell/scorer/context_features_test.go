@@ -0,0 +1,47 @@
+package scorer
+
+import (
+	"reflect"
+	"spell"
+	"testing"
+)
+
+func TestContextTokensOfDoubleLetterEdits(t *testing.T) {
+	cases := []struct {
+		term, input string
+		action      spell.EditAction
+		tokens      []string
+	}{
+		{"abcd", "abbcd", spell.Duplicate, []string{
+			join("duplicate", "from", "", "to", "b"),
+			join("duplicate", "b", "left", "b"),
+			join("duplicate", "b", "right", "c"),
+			join("duplicate", "classes", "consonant", "consonant", "consonant"),
+			join("duplicate", "double", "b"),
+		}},
+		{"bbbca", "bb", spell.MissDouble, []string{
+			join("miss-double", "from", "b", "to", ""),
+			join("miss-double", "b", "left", "b"),
+			join("miss-double", "b", "right", "c"),
+			join("miss-double", "classes", "consonant", "consonant", "consonant"),
+			join("miss-double", "double", "b"),
+		}},
+	}
+	measurer := spell.NewDistanceMeasurer()
+	for _, c := range cases {
+		_, prescription := measurer.Distance(c.term, c.input, true)
+		found := false
+		for _, edit := range prescription.Edits() {
+			if edit.Action != c.action {
+				continue
+			}
+			found = true
+			if tokens := contextTokens(prescription, edit); !reflect.DeepEqual(tokens, c.tokens) {
+				t.Errorf("%s→%s: tokens %q, expected %q", c.term, c.input, tokens, c.tokens)
+			}
+		}
+		if !found {
+			t.Errorf("%s→%s: prescription %s has no %s", c.term, c.input, prescription, c.action)
+		}
+	}
+}
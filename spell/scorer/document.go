@@ -0,0 +1,121 @@
+package scorer
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+)
+
+/**
+	Version of the JSON scorer format, documents of other versions are refused
+ */
+const FormatVersion = 1
+
+type NamedWeight struct {
+	Name   string  `json:"name"`
+	Weight float64 `json:"weight"`
+}
+
+/**
+	Document is the JSON form of the scorers keeping a weight per vector slot
+ */
+type Document struct {
+	FormatVersion int              `json:"format_version"`
+	Type          string           `json:"type"`
+	Layout        string           `json:"layout,omitempty"`
+	Features      []string         `json:"features"`
+	Weights       []NamedWeight    `json:"weights"`
+	Metadata      TrainingMetadata `json:"metadata"`
+}
+
+/**
+	NewDocument names every weight by its slot, so there must be a slot name per weight
+ */
+func NewDocument(scorerType string, weights *Vector, slotNames []string, metadata TrainingMetadata, vectoriser *Vectoriser) (*Document, error) {
+	if weights != nil && weights.Len() != len(slotNames) {
+		return nil, fmt.Errorf("scorer has %d weights for %d slot names", weights.Len(), len(slotNames))
+	}
+	document := &Document{
+		FormatVersion: FormatVersion,
+		Type:          scorerType,
+		Features:      vectoriser.FeatureNames,
+		Weights:       []NamedWeight{},
+		Metadata:      metadata,
+	}
+	if document.Features == nil {
+		document.Features = EditFeatures
+	}
+	if vectoriser.Layout != nil {
+		document.Layout = vectoriser.Layout.Name
+	}
+	if weights != nil {
+		for i, weight := range weights.Xs {
+			document.Weights = append(document.Weights, NamedWeight{Name: slotNames[i], Weight: weight})
+		}
+	}
+	return document, nil
+}
+
+func (document *Document) Write(w io.Writer) error {
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(document)
+}
+
+/**
+	ReadDocument refuses documents of other format versions or scorer types
+ */
+func ReadDocument(r io.Reader, scorerType string) (*Document, error) {
+	document := &Document{}
+	if err := json.NewDecoder(r).Decode(document); err != nil {
+		return nil, err
+	}
+	if document.FormatVersion != FormatVersion {
+		return nil, fmt.Errorf("scorer format version is %d, %d is supported", document.FormatVersion, FormatVersion)
+	}
+	if document.Type != scorerType {
+		return nil, fmt.Errorf("scorer type is %q, %q is expected", document.Type, scorerType)
+	}
+	return document, nil
+}
+
+/**
+	Weights checked against the vectoriser, and the slot names they were trained with.
+	Both the features and the slot names of the document must be the ones of the vectoriser
+ */
+func (document *Document) CheckedWeights(vectoriser *Vectoriser) (*Vector, []string, error) {
+	if vectoriser.Layout != nil && document.Layout != "" && document.Layout != vectoriser.Layout.Name {
+		return nil, nil, fmt.Errorf("scorer was trained with layout %q but the vectoriser has %q", document.Layout, vectoriser.Layout.Name)
+	}
+	if err := document.checkFeatureNames(vectoriser); err != nil {
+		return nil, nil, err
+	}
+	var (
+		weights   = InitVector(len(document.Weights))
+		slotNames = make([]string, len(document.Weights))
+	)
+	for i, namedWeight := range document.Weights {
+		weights.Xs[i] = namedWeight.Weight
+		slotNames[i] = namedWeight.Name
+	}
+	if err := CheckFeatures(slotNames, document.Metadata.FeaturesVersion, weights, vectoriser); err != nil {
+		return nil, nil, err
+	}
+	return weights, slotNames, nil
+}
+
+func (document *Document) checkFeatureNames(vectoriser *Vectoriser) error {
+	current := vectoriser.FeatureNames
+	if current == nil {
+		current = EditFeatures
+	}
+	if len(document.Features) != len(current) {
+		return fmt.Errorf("scorer was trained with %d features but the vectoriser has %d", len(document.Features), len(current))
+	}
+	for i := range document.Features {
+		if document.Features[i] != current[i] {
+			return fmt.Errorf("feature %d of the scorer is %q but the vectoriser has %q", i, document.Features[i], current[i])
+		}
+	}
+	return nil
+}
@@ -0,0 +1,106 @@
+package scorer
+
+import (
+	"bytes"
+	"reflect"
+	"spell/keyboard"
+	"testing"
+)
+
+func TestCheckedWeightsComparesFeatures(t *testing.T) {
+	vectoriser, err := InitVectoriserWithFeatures(keyboard.Qwerty, ExtendedFeatures)
+	if err != nil {
+		t.Fatal(err)
+	}
+	buffer := &bytes.Buffer{}
+	weights := InitVector(len(vectoriser.SlotNames()))
+	document, err := NewDocument("linear", weights, vectoriser.SlotNames(), TrainingMetadata{FeaturesVersion: FeaturesVersion}, vectoriser)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err = document.Write(buffer); err != nil {
+		t.Fatal(err)
+	}
+	cases := []struct {
+		features   []string
+		isAccepted bool
+	}{
+		{ExtendedFeatures, true},
+		{EditFeatures, false},
+		{append([]string{"input-length"}, ExtendedFeatures[1:]...), false},
+	}
+	for _, c := range cases {
+		document, err := ReadDocument(bytes.NewReader(buffer.Bytes()), "linear")
+		if err != nil {
+			t.Fatal(err)
+		}
+		document.Features = c.features
+		if _, _, err = document.CheckedWeights(vectoriser); (err == nil) != c.isAccepted {
+			t.Errorf("features %v: error %v", c.features, err)
+		}
+	}
+}
+
+func TestCheckedWeightsRefusesOtherFeaturesVersion(t *testing.T) {
+	vectoriser := InitVectoriser()
+	buffer := &bytes.Buffer{}
+	metadata := TrainingMetadata{Learner: "stochastic", FeaturesVersion: FeaturesVersion - 1}
+	document, err := NewDocument("linear", InitVector(vectoriser.Size()), vectoriser.SlotNames(), metadata, vectoriser)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err = document.Write(buffer); err != nil {
+		t.Fatal(err)
+	}
+	document, err = ReadDocument(buffer, "linear")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, _, err = document.CheckedWeights(vectoriser); err == nil {
+		t.Error("weights of features of a previous version are accepted")
+	}
+}
+
+func TestDocumentRoundTrip(t *testing.T) {
+	vectoriser := InitVectoriser()
+	weights := InitVector(vectoriser.Size())
+	for i := range weights.Xs {
+		weights.Xs[i] = float64(i) / 10
+	}
+	document, err := NewDocument("linear", weights, vectoriser.SlotNames(), TrainingMetadata{FeaturesVersion: FeaturesVersion}, vectoriser)
+	if err != nil {
+		t.Fatal(err)
+	}
+	buffer := &bytes.Buffer{}
+	if err = document.Write(buffer); err != nil {
+		t.Fatal(err)
+	}
+	if document, err = ReadDocument(buffer, "linear"); err != nil {
+		t.Fatal(err)
+	}
+	readWeights, slotNames, err := document.CheckedWeights(vectoriser)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(readWeights, weights) || !reflect.DeepEqual(slotNames, vectoriser.SlotNames()) {
+		t.Errorf("read weights %v of %v, expected %v of %v", readWeights, slotNames, weights, vectoriser.SlotNames())
+	}
+}
+
+func TestNewDocumentChecksSlotNames(t *testing.T) {
+	vectoriser := InitVectoriser()
+	weights := InitVector(vectoriser.Size())
+	for _, slotNames := range [][]string{nil, vectoriser.SlotNames()[1:]} {
+		if _, err := NewDocument("linear", weights, slotNames, TrainingMetadata{}, vectoriser); err == nil {
+			t.Errorf("%d weights are named by %d slot names", weights.Len(), len(slotNames))
+		}
+	}
+	// a vectoriser without feature names has the edit features
+	document, err := NewDocument("linear", weights, vectoriser.SlotNames(), TrainingMetadata{}, &Vectoriser{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(document.Features, EditFeatures) {
+		t.Errorf("features are %v, expected the edit features", document.Features)
+	}
+}
@@ -0,0 +1,104 @@
+package scorer
+
+import (
+	"spell"
+)
+
+/**
+	Features of the original vectoriser, in the order of the slot constants
+ */
+var EditFeatures = []string{
+	"replace-first",
+	"replace-consonant",
+	"replace-key-1",
+	"replace-key-2",
+	"replace-key-3",
+	"replace-key-4",
+	"replace-key-other",
+	"delete-first",
+	"delete-last",
+	"delete-middle",
+	"insert-first",
+	"insert-middle",
+	"transposition",
+	"miss-double",
+	"duplicate",
+	"triplet",
+}
+
+func init() {
+	for slot, name := range EditFeatures {
+		mustRegisterFeature(&Feature{
+			Name:    name,
+			Extract: editFeature(slot),
+		})
+	}
+}
+
+/**
+	Counts edits of the suggestion prescription falling into the slot
+ */
+func editFeature(slot int) FeatureExtractor {
+	return func(vectoriser *Vectoriser, suggestion *Extraction, xs []float64) {
+		for _, editSlot := range suggestion.editSlots(vectoriser) {
+			if editSlot == slot {
+				xs[0] += 1
+			}
+		}
+	}
+}
+
+/**
+	Slot of the edit starting at i, -1 for replacements of chars missing on the keyboard
+ */
+func (maker *Vectoriser) editSlot(prescription *spell.EditorialPrescription, i int) int {
+	from := prescription.Froms[i]
+	to := prescription.Tos[i]
+	switch prescription.Actions[i] {
+	case spell.Replace:
+		if i == 0 {
+			return RFirst
+		}
+		if maker.consonantDistance(from, to) >= 0 {
+			return RConsonant
+		}
+		keyDistance := maker.keyDistance(from, to)
+		switch true {
+		case keyDistance < 0:
+			return -1
+		case keyDistance <= 1+eps:
+			return RDistance1
+		case keyDistance <= 2+eps:
+			return RDistance2
+		case keyDistance <= 3+eps:
+			return RDistance3
+		case keyDistance <= 4+eps:
+			return RDistance4
+		default:
+			return RDistanceOther
+		}
+	case spell.Delete:
+		switch true {
+		case i == 0:
+			return DFirst
+		case i == len(prescription.Actions)-1:
+			return DLast
+		default:
+			return DMiddle
+		}
+	case spell.Insert:
+		if i == 0 {
+			return IFirst
+		}
+		return IMiddle
+	case spell.Transposition:
+		return T
+	case spell.Duplicate:
+		return P
+	case spell.MissDouble:
+		return U
+	case spell.Triplet:
+		return J
+	}
+	return -1
+}
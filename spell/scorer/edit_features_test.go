@@ -0,0 +1,45 @@
+package scorer
+
+import (
+	"spell"
+	"testing"
+)
+
+func TestEditFeaturesCountEveryEdit(t *testing.T) {
+	cases := []struct {
+		term, input string
+		action      spell.EditAction
+	}{
+		{"cbb", "abbaab", spell.Duplicate},
+		{"bbbca", "bb", spell.MissDouble},
+		{"acaab", "bbca", spell.MissDouble},
+	}
+	var (
+		measurer   = spell.NewDistanceMeasurer()
+		vectoriser = InitVectoriser()
+	)
+	for _, c := range cases {
+		_, prescription := measurer.Distance(c.term, c.input, true)
+		var (
+			edits     = 0
+			hasAction = false
+		)
+		for _, edit := range prescription.Edits() {
+			if edit.Action != spell.Match {
+				edits++
+			}
+			hasAction = hasAction || edit.Action == c.action
+		}
+		if !hasAction {
+			t.Fatalf("%s→%s: prescription %s has no %s", c.term, c.input, prescription, c.action)
+		}
+
+		counted := 0.0
+		for _, x := range vectoriser.Vectorize(prescription).Xs {
+			counted += x
+		}
+		if counted != float64(edits) {
+			t.Errorf("%s→%s: edit features count %v edits, prescription %s has %d", c.term, c.input, counted, prescription, edits)
+		}
+	}
+}
@@ -0,0 +1,166 @@
+package ensemble
+
+import (
+	"fmt"
+	"math"
+	"spell"
+	"spell/scorer"
+	"spell/scorer/linear"
+	"time"
+)
+
+type LearnProgress struct {
+	PairsCount int
+	Epoch      int
+	Loss       float64
+}
+
+/**
+	Learner fits WeightedSum weights of trained members with linear.PairwiseFit,
+	a pair is the score differences of the members between a wrong candidate and the correct one.
+	The learning data should be held out from the data the members were trained on,
+	e.g. the test part of evaluation.Splitter.Holdout
+ */
+type Learner struct {
+	Members []spell.ScoreModel
+	Names   []string
+	linear.PairwiseFit
+	OnEvent       spell.LearnEventHandler
+	learnProgress LearnProgress
+}
+
+func InitLearner(members []spell.ScoreModel) *Learner {
+	return &Learner{
+		Members: members,
+		PairwiseFit: linear.PairwiseFit{
+			LearningRate: 0.5,
+			L2:           0.001,
+			Epochs:       300,
+		},
+	}
+}
+
+func (learner *Learner) Learn(learningData []*spell.LearningTerm) spell.ScoreModel {
+	learner.learnProgress = LearnProgress{}
+	startedAt := time.Now()
+	differences := learner.differences(learningData)
+	learner.learnProgress.PairsCount = len(differences)
+
+	// members score on different scales, the fit is done on unit variance differences
+	scales := standardize(differences, len(learner.Members))
+	fitted := learner.Fit(differences, func(epoch int, loss float64, weights *scorer.Vector) {
+		learner.learnProgress.Epoch = epoch
+		learner.learnProgress.Loss = loss
+		if learner.OnEvent != nil {
+			learner.OnEvent(spell.LearnEvent{
+				Learner: "ensemble",
+				Step:    epoch,
+				Loss:    loss,
+				Elapsed: time.Since(startedAt),
+				Weights: append([]float64(nil), weights.Xs...),
+			})
+		}
+	})
+
+	weights := make([]float64, len(learner.Members))
+	for i := range weights {
+		weights[i] = 1
+		if fitted != nil {
+			weights[i] = fitted.Xs[i] / scales[i]
+		}
+	}
+	if learner.OnEvent != nil {
+		learner.OnEvent(spell.LearnEvent{
+			Learner: "ensemble",
+			Step:    learner.learnProgress.Epoch,
+			Loss:    learner.learnProgress.Loss,
+			Elapsed: time.Since(startedAt),
+			Weights: append([]float64(nil), weights...),
+			IsFinal: true,
+		})
+	}
+	return &Scorer{
+		Members: learner.Members,
+		Names:   learner.Names,
+		Weights: weights,
+		Metadata: scorer.TrainingMetadata{
+			Learner: "ensemble",
+		},
+	}
+}
+
+func (learner *Learner) LearnProgress() string {
+	learnProgress := learner.learnProgress
+	return fmt.Sprintf("Epoch %d. Loss: %f. Pairs count: %d", learnProgress.Epoch, learnProgress.Loss, learnProgress.PairsCount)
+}
+
+/**
+	Pairs with infinite or undefined member scores are skipped
+ */
+func (learner *Learner) differences(learningData []*spell.LearningTerm) []*scorer.Vector {
+	var differences []*scorer.Vector
+	for _, learningTerm := range learningData {
+		correct := -1
+		for i := range learningTerm.Suggestions {
+			if learningTerm.Suggestions[i].Term == learningTerm.Term {
+				correct = i
+				break
+			}
+		}
+		if correct < 0 {
+			continue
+		}
+		correctScores := learner.scores(&learningTerm.Suggestions[correct])
+		for i := range learningTerm.Suggestions {
+			suggestion := &learningTerm.Suggestions[i]
+			if i == correct || suggestion.Term == learningTerm.Misspell {
+				continue
+			}
+			difference := learner.scores(suggestion).Sub(correctScores)
+			isValid := true
+			for _, x := range difference.Xs {
+				isValid = isValid && isFinite(x)
+			}
+			if isValid {
+				differences = append(differences, difference)
+			}
+		}
+	}
+	return differences
+}
+
+func (learner *Learner) scores(suggestion *spell.Suggestion) *scorer.Vector {
+	scores := scorer.InitVector(len(learner.Members))
+	for i, member := range learner.Members {
+		scores.Xs[i] = member.Score(suggestion)
+	}
+	return scores
+}
+
+/**
+	Divide every coordinate of the differences by its root mean square, the divisors are returned
+ */
+func standardize(differences []*scorer.Vector, dimension int) []float64 {
+	scales := make([]float64, dimension)
+	for _, difference := range differences {
+		for i, x := range difference.Xs {
+			scales[i] += x * x
+		}
+	}
+	for i := range scales {
+		scales[i] = math.Sqrt(scales[i] / float64(len(differences)))
+		if scales[i] == 0 || math.IsNaN(scales[i]) {
+			scales[i] = 1
+		}
+	}
+	for _, difference := range differences {
+		for i := range difference.Xs {
+			difference.Xs[i] /= scales[i]
+		}
+	}
+	return scales
+}
+
+func isFinite(x float64) bool {
+	return !math.IsInf(x, 0) && !math.IsNaN(x)
+}
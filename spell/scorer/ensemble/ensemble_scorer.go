@@ -0,0 +1,157 @@
+package ensemble
+
+import (
+	"fmt"
+	"sort"
+	"spell"
+	"spell/scorer"
+)
+
+type Combination uint8
+
+const (
+	WeightedSum Combination = 0
+	RankFusion  Combination = 1
+)
+
+const DefaultRankFusionK = 60
+
+/**
+	Scorer combines scores of member models, lower is better as for every member.
+	WeightedSum takes Σ wᵢ·scoreᵢ. RankFusion takes -Σ wᵢ/(K + rankᵢ) with ranks of the candidate
+	among all the candidates of the input, so it needs the batch scoring GetSuggestions does;
+	a suggestion scored alone gets the weighted sum
+ */
+type Scorer struct {
+	Members     []spell.ScoreModel
+	Names       []string  // "member-i" if not set
+	Weights     []float64 // a weight per member, 1 for members past the end
+	Combination Combination
+	K           float64 // rank fusion constant, DefaultRankFusionK if not set
+	Metadata    scorer.TrainingMetadata
+}
+
+/**
+	Weighted sum of the members, there must be a weight per member
+ */
+func NewScorer(members []spell.ScoreModel, weights []float64) (*Scorer, error) {
+	if len(weights) != len(members) {
+		return nil, fmt.Errorf("ensemble has %d weights for %d members", len(weights), len(members))
+	}
+	return &Scorer{
+		Members: members,
+		Weights: weights,
+	}, nil
+}
+
+/**
+	Rank fusion of the members with equal weights
+ */
+func NewRankFusionScorer(members []spell.ScoreModel) *Scorer {
+	weights := make([]float64, len(members))
+	for i := range weights {
+		weights[i] = 1
+	}
+	return &Scorer{
+		Members:     members,
+		Weights:     weights,
+		Combination: RankFusion,
+		K:           DefaultRankFusionK,
+	}
+}
+
+func (scoring *Scorer) Compare(a *spell.Suggestion, b *spell.Suggestion) float64 {
+	a.Score = scoring.Score(a)
+	b.Score = scoring.Score(b)
+	return a.Score - b.Score
+}
+
+func (scoring *Scorer) Score(a *spell.Suggestion) float64 {
+	score := 0.0
+	for i, member := range scoring.Members {
+		score += scoring.weight(i) * member.Score(a)
+	}
+	return score
+}
+
+func (scoring *Scorer) ScoreAll(suggestions []spell.Suggestion) {
+	if scoring.Combination != RankFusion {
+		for i := range suggestions {
+			suggestions[i].Score = scoring.Score(&suggestions[i])
+		}
+		return
+	}
+
+	var (
+		k      = scoring.K
+		fused  = make([]float64, len(suggestions))
+		scores = make([]float64, len(suggestions))
+		order  = make([]int, len(suggestions))
+	)
+	if k <= 0 {
+		k = DefaultRankFusionK
+	}
+	for m, member := range scoring.Members {
+		for i := range suggestions {
+			scores[i] = member.Score(&suggestions[i])
+			order[i] = i
+		}
+		sort.SliceStable(order, func(i, j int) bool {
+			return scores[order[i]] < scores[order[j]]
+		})
+		// equal scores share the rank of the first of them
+		rank := 1
+		for position, i := range order {
+			if position > 0 && scores[i] != scores[order[position-1]] {
+				rank = position + 1
+			}
+			fused[i] -= scoring.weight(m) / (k + float64(rank))
+		}
+	}
+	for i := range suggestions {
+		suggestions[i].Score = fused[i]
+	}
+}
+
+/**
+	A contribution per member: its score, its weight and their product, they sum up to Score.
+	Rank fusion depends on the other candidates, so it is explained by the weighted sum as well
+ */
+func (scoring *Scorer) ExplainScore(a *spell.Suggestion) []spell.FeatureContribution {
+	contributions := make([]spell.FeatureContribution, len(scoring.Members))
+	for i, member := range scoring.Members {
+		score := member.Score(a)
+		contributions[i] = spell.FeatureContribution{
+			Name:         scoring.memberName(i),
+			Value:        score,
+			Weight:       scoring.weight(i),
+			Contribution: scoring.weight(i) * score,
+		}
+	}
+	return contributions
+}
+
+/**
+	Contributions of the features of member i, nil if the member can't explain its scores
+ */
+func (scoring *Scorer) ExplainMember(i int, a *spell.Suggestion) []spell.FeatureContribution {
+	explainer, ok := scoring.Members[i].(spell.ScoreExplainer)
+	if !ok {
+		return nil
+	}
+	return explainer.ExplainScore(a)
+}
+
+func (scoring *Scorer) weight(i int) float64 {
+	if i >= len(scoring.Weights) {
+		return 1
+	}
+	return scoring.Weights[i]
+}
+
+func (scoring *Scorer) memberName(i int) string {
+	if i < len(scoring.Names) && scoring.Names[i] != "" {
+		return scoring.Names[i]
+	}
+	return fmt.Sprintf("member-%d", i)
+}
@@ -0,0 +1,123 @@
+package ensemble
+
+import (
+	"spell"
+	"testing"
+)
+
+// scores by term
+type termScorer map[string]float64
+
+func (scoring termScorer) Score(a *spell.Suggestion) float64 {
+	return scoring[a.Term]
+}
+
+func (scoring termScorer) Compare(a *spell.Suggestion, b *spell.Suggestion) float64 {
+	return scoring.Score(a) - scoring.Score(b)
+}
+
+func suggestionsOf(terms ...string) []spell.Suggestion {
+	suggestions := make([]spell.Suggestion, len(terms))
+	for i, term := range terms {
+		suggestions[i] = spell.Suggestion{Term: term}
+	}
+	return suggestions
+}
+
+func TestNewScorerChecksWeights(t *testing.T) {
+	members := []spell.ScoreModel{termScorer{}, termScorer{}}
+	if _, err := NewScorer(members, []float64{1}); err == nil {
+		t.Error("a weight for two members is accepted")
+	}
+	if _, err := NewScorer(members, nil); err == nil {
+		t.Error("no weights for two members are accepted")
+	}
+	if _, err := NewScorer(members, []float64{1, 2}); err != nil {
+		t.Errorf("a weight per member is refused: %v", err)
+	}
+}
+
+func TestWeightedSumOrder(t *testing.T) {
+	members := []spell.ScoreModel{
+		termScorer{"held": 1, "help": 2, "hell": 3},
+		termScorer{"held": 3, "help": 1, "hell": 0},
+	}
+	cases := []struct {
+		weights  []float64
+		expected []float64
+	}{
+		{[]float64{1, 1}, []float64{4, 3, 3}},
+		{[]float64{1, 0}, []float64{1, 2, 3}},
+		{[]float64{0.5, 2}, []float64{6.5, 3, 1.5}},
+	}
+	for _, c := range cases {
+		scoring, err := NewScorer(members, c.weights)
+		if err != nil {
+			t.Fatal(err)
+		}
+		suggestions := suggestionsOf("held", "help", "hell")
+		scoring.ScoreAll(suggestions)
+		for i, suggestion := range suggestions {
+			if suggestion.Score != c.expected[i] {
+				t.Errorf("weights %v: %s has score %v, expected %v", c.weights, suggestion.Term, suggestion.Score, c.expected[i])
+			}
+			if score := scoring.Score(&suggestions[i]); score != suggestion.Score {
+				t.Errorf("weights %v: %s scored alone has score %v, in a batch %v", c.weights, suggestion.Term, score, suggestion.Score)
+			}
+		}
+	}
+}
+
+func TestRankFusionSharesRanksOfTies(t *testing.T) {
+	scoring := NewRankFusionScorer([]spell.ScoreModel{
+		termScorer{"held": 1, "help": 2, "hell": 2, "hello": 3},
+	})
+	suggestions := suggestionsOf("hello", "hell", "help", "held")
+	scoring.ScoreAll(suggestions)
+	// ties share the rank of the first of them and the next candidate is ranked by its position
+	expected := map[string]float64{
+		"held":  -1.0 / 61,
+		"help":  -1.0 / 62,
+		"hell":  -1.0 / 62,
+		"hello": -1.0 / 64,
+	}
+	for _, suggestion := range suggestions {
+		if suggestion.Score != expected[suggestion.Term] {
+			t.Errorf("%s has score %v, expected %v", suggestion.Term, suggestion.Score, expected[suggestion.Term])
+		}
+	}
+}
+
+func TestRankFusionOrder(t *testing.T) {
+	scoring := NewRankFusionScorer([]spell.ScoreModel{
+		termScorer{"held": 1, "help": 2, "hell": 3},
+		// scales don't matter, ranks do
+		termScorer{"held": 300, "help": 100, "hell": 200},
+	})
+	scoring.Weights[1] = 2
+	suggestions := suggestionsOf("held", "help", "hell")
+	scoring.ScoreAll(suggestions)
+	expected := []float64{
+		-(1.0/61 + 2.0/63),
+		-(1.0/62 + 2.0/61),
+		-(1.0/63 + 2.0/62),
+	}
+	for i, suggestion := range suggestions {
+		if suggestion.Score != expected[i] {
+			t.Errorf("%s has score %v, expected %v", suggestion.Term, suggestion.Score, expected[i])
+		}
+	}
+	if !(suggestions[1].Score < suggestions[0].Score && suggestions[0].Score < suggestions[2].Score) {
+		t.Errorf("scores %v, expected help < held < hell", []float64{suggestions[0].Score, suggestions[1].Score, suggestions[2].Score})
+	}
+}
+
+func TestMissingWeightsAreOne(t *testing.T) {
+	scoring := &Scorer{
+		Members: []spell.ScoreModel{termScorer{"held": 1}, termScorer{"held": 2}},
+		Weights: []float64{3},
+	}
+	if score := scoring.Score(&spell.Suggestion{Term: "held"}); score != 5 {
+		t.Errorf("score is %v, expected 5", score)
+	}
+}
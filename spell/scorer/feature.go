@@ -0,0 +1,131 @@
+package scorer
+
+import (
+	"fmt"
+	"spell"
+	"sync"
+)
+
+/**
+	Version of what the features measure, scorers trained with features of another version are refused.
+	It is bumped when a feature changes its values but keeps its name.
+	Version 2 counts duplicates and miss-doubles as single edits and takes the right context from the last step
+ */
+const FeaturesVersion = 2
+
+/**
+	FeatureExtractor adds values of the feature to xs, xs has as many slots as the feature has
+ */
+type FeatureExtractor func(vectoriser *Vectoriser, suggestion *Extraction, xs []float64)
+
+/**
+	Extraction is the suggestion being vectorised, what several features need is computed once for all of them
+ */
+type Extraction struct {
+	*spell.Suggestion
+	edits      []spell.Edit
+	slots      []int
+	isEditsSet bool
+	isSlotsSet bool
+}
+
+func NewExtraction(suggestion *spell.Suggestion) *Extraction {
+	return &Extraction{
+		Suggestion: suggestion,
+	}
+}
+
+/**
+	Edits of the prescription except matches, exact matches and layout fixes have no prescription and no edits
+ */
+func (extraction *Extraction) Edits() []spell.Edit {
+	if extraction.isEditsSet {
+		return extraction.edits
+	}
+	extraction.isEditsSet = true
+	if extraction.Prescription == nil {
+		return nil
+	}
+	for _, edit := range extraction.Prescription.Edits() {
+		if edit.Action != spell.Match {
+			extraction.edits = append(extraction.edits, edit)
+		}
+	}
+	return extraction.edits
+}
+
+/**
+	Edit feature slots of the edits
+ */
+func (extraction *Extraction) editSlots(vectoriser *Vectoriser) []int {
+	if extraction.isSlotsSet {
+		return extraction.slots
+	}
+	extraction.isSlotsSet = true
+	for _, edit := range extraction.Edits() {
+		extraction.slots = append(extraction.slots, vectoriser.editSlot(extraction.Prescription, edit.Steps[0]))
+	}
+	return extraction.slots
+}
+
+/**
+	Feature is a named group of vector slots, most features have one slot
+ */
+type Feature struct {
+	Name    string
+	Size    int // 1 if not set
+	Extract FeatureExtractor
+}
+
+var (
+	features      = map[string]*Feature{}
+	featuresMutex sync.RWMutex
+)
+
+func (feature *Feature) size() int {
+	if feature.Size > 0 {
+		return feature.Size
+	}
+	return 1
+}
+
+/**
+	Names of the slots of the feature, "name" for a single slot and "name[i]" otherwise
+ */
+func (feature *Feature) SlotNames() []string {
+	size := feature.size()
+	if size == 1 {
+		return []string{feature.Name}
+	}
+	names := make([]string, size)
+	for i := range names {
+		names[i] = fmt.Sprintf("%s[%d]", feature.Name, i)
+	}
+	return names
+}
+
+func RegisterFeature(feature *Feature) error {
+	if feature.Name == "" || feature.Extract == nil {
+		return fmt.Errorf("feature must have a name and an extractor")
+	}
+	featuresMutex.Lock()
+	defer featuresMutex.Unlock()
+	if _, ok := features[feature.Name]; ok {
+		return fmt.Errorf("feature %q is already registered", feature.Name)
+	}
+	features[feature.Name] = feature
+	return nil
+}
+
+func FeatureByName(name string) (*Feature, bool) {
+	featuresMutex.RLock()
+	defer featuresMutex.RUnlock()
+	feature, ok := features[name]
+	return feature, ok
+}
+
+func mustRegisterFeature(feature *Feature) {
+	if err := RegisterFeature(feature); err != nil {
+		panic(err)
+	}
+}
@@ -1,15 +1,27 @@
 package linear
 
 import (
+	"io"
 	"spell"
 	"spell/scorer"
 )
 
+const ScorerType = "linear"
+
 type Scorer struct {
-	Weights *scorer.Vector
+	Weights      *scorer.Vector
+	FeatureNames []string // slot names of the vectoriser the weights were trained with
+	Metadata     scorer.TrainingMetadata
 	*scorer.Vectoriser
 }
 
+/**
+	Validate fails if the weights don't fit the vectoriser, e.g. after loading a scorer trained with other features
+ */
+func (scoring *Scorer) Validate() error {
+	return scorer.CheckFeatures(scoring.FeatureNames, scoring.Metadata.FeaturesVersion, scoring.Weights, scoring.Vectoriser)
+}
+
 func (scoring *Scorer) Compare(a *spell.Suggestion, b *spell.Suggestion) float64 {
 	largeValue := 100.0
 	if a.Prescription == nil {
@@ -19,8 +31,8 @@ func (scoring *Scorer) Compare(a *spell.Suggestion, b *spell.Suggestion) float64
 		return largeValue
 	}
 
-	vectorA := scoring.Vectorize(a.Prescription)
-	vectorB := scoring.Vectorize(b.Prescription)
+	vectorA := scoring.VectorizeSuggestion(a)
+	vectorB := scoring.VectorizeSuggestion(b)
 	a.Score = vectorA.ScalarMul(scoring.Weights)
 	b.Score = vectorB.ScalarMul(scoring.Weights)
 	if vectorA.Sub(vectorB).IsSatisfied(scoring.Weights) {
@@ -35,16 +47,16 @@ func (scoring *Scorer) Score(a *spell.Suggestion) float64 {
 		return largeValue
 	}
 
-	vectorA := scoring.Vectorize(a.Prescription)
+	vectorA := scoring.VectorizeSuggestion(a)
 	score := vectorA.ScalarMul(scoring.Weights)
 	return score
 }
 
 func (scoring *Scorer) GetVectorSystem(a *spell.LearningTerm) *VectorSystem {
 	baseVector := (*scorer.Vector)(nil)
-	for _, suggestion := range a.Suggestions {
-		if suggestion.Term == a.Term {
-			baseVector = scoring.Vectorize(suggestion.Prescription)
+	for i := range a.Suggestions {
+		if a.Suggestions[i].Term == a.Term {
+			baseVector = scoring.VectorizeSuggestion(&a.Suggestions[i])
 			break
 		}
 	}
@@ -53,9 +65,10 @@ func (scoring *Scorer) GetVectorSystem(a *spell.LearningTerm) *VectorSystem {
 	}
 
 	vectorSystem := InitVectorSystem()
-	for _, suggestion := range a.Suggestions {
+	for i := range a.Suggestions {
+		suggestion := &a.Suggestions[i]
 		if suggestion.Term != a.Term && a.Misspell != suggestion.Term {
-			vector := scoring.Vectorize(suggestion.Prescription)
+			vector := scoring.VectorizeSuggestion(suggestion)
 			vector = vector.Sub(baseVector)
 			vectorSystem.Add(vector)
 			break
@@ -64,3 +77,69 @@ func (scoring *Scorer) GetVectorSystem(a *spell.LearningTerm) *VectorSystem {
 	vectorSystem.Normalize()
 	return vectorSystem
 }
+
+/**
+	Contributions of the features with non zero values, they sum up to Score.
+	Suggestions without a prescription aren't scored by features and have no contributions
+ */
+func (scoring *Scorer) ExplainScore(a *spell.Suggestion) []spell.FeatureContribution {
+	if a.Prescription == nil {
+		return nil
+	}
+	var (
+		vector        = scoring.VectorizeSuggestion(a)
+		names         = scoring.SlotNames()
+		contributions []spell.FeatureContribution
+	)
+	for i, value := range vector.Xs {
+		if value == 0 {
+			continue
+		}
+		contributions = append(contributions, spell.FeatureContribution{
+			Name:         names[i],
+			Value:        value,
+			Weight:       scoring.weight(i),
+			Contribution: value * scoring.weight(i),
+		})
+	}
+	return contributions
+}
+
+// weight of the slot, a scorer without weights weighs every slot 0
+func (scoring *Scorer) weight(i int) float64 {
+	if scoring.Weights == nil || i >= scoring.Weights.Len() {
+		return 0
+	}
+	return scoring.Weights.Xs[i]
+}
+
+/**
+	Write the scorer as a JSON document, weights are listed with their feature slot names
+ */
+func (scoring *Scorer) WriteJSON(w io.Writer) error {
+	document, err := scorer.NewDocument(ScorerType, scoring.Weights, scoring.FeatureNames, scoring.Metadata, scoring.Vectoriser)
+	if err != nil {
+		return err
+	}
+	return document.Write(w)
+}
+
+/**
+	ReadScorerJSON reads a scorer written by WriteJSON, it fails if the weights don't fit the vectoriser
+ */
+func ReadScorerJSON(r io.Reader, vectoriser *scorer.Vectoriser) (*Scorer, error) {
+	document, err := scorer.ReadDocument(r, ScorerType)
+	if err != nil {
+		return nil, err
+	}
+	weights, slotNames, err := document.CheckedWeights(vectoriser)
+	if err != nil {
+		return nil, err
+	}
+	return &Scorer{
+		Weights:      weights,
+		FeatureNames: slotNames,
+		Metadata:     document.Metadata,
+		Vectoriser:   vectoriser,
+	}, nil
+}
@@ -0,0 +1,24 @@
+package linear
+
+import (
+	"spell"
+	"spell/scorer"
+	"testing"
+)
+
+func TestExplainScoreWithoutWeights(t *testing.T) {
+	_, prescription := spell.NewDistanceMeasurer().Distance("text", "tect", true)
+	scoring := &Scorer{Vectoriser: scorer.InitVectoriser()}
+	for _, contribution := range scoring.ExplainScore(&spell.Suggestion{Term: "text", Prescription: prescription}) {
+		if contribution.Contribution != 0 {
+			t.Errorf("%s contributes %v without weights", contribution.Name, contribution.Contribution)
+		}
+	}
+}
+
+func TestExplainScoreWithoutPrescription(t *testing.T) {
+	scoring := &Scorer{Weights: scorer.InitVector(scorer.InitVectoriser().Size()), Vectoriser: scorer.InitVectoriser()}
+	if contributions := scoring.ExplainScore(&spell.Suggestion{Term: "text"}); len(contributions) != 0 {
+		t.Errorf("an exact match is explained by %v", contributions)
+	}
+}
@@ -0,0 +1,251 @@
+package linear
+
+import (
+	"fmt"
+	"math"
+	"spell"
+	"spell/scorer"
+	"time"
+)
+
+type LPLearnReport struct {
+	VectorSystemsCount int
+	SatisfiedCount     int
+	Iterations         int
+	TotalSlack         float64 // soft margin objective of the found weights
+	LowerBound         float64 // no weights have smaller objective
+	IsConverged        bool    // TotalSlack is within Tolerance of LowerBound
+	Err                error   // the master program failure that stopped the learning, if any
+	Conflicts          []*spell.LearningTerm
+}
+
+/**
+	LPLearner solves the soft margin linear program over vector systems:
+
+	min Σ ξs subject to d·w >= 1 - ξs for every inequality d of system s, 0 <= w <= MaxWeight, ξ >= 0
+
+	Kelley's cutting plane method is used: every iteration adds a cut of the piecewise linear objective
+	and the master program is solved exactly by simplex, so the found weights are optimal within Tolerance.
+	Learning terms whose systems the optimal weights don't satisfy conflict with the others and are reported.
+	Weights of a learning stopped by MaxIterations or a master program failure aren't optimal,
+	the report tells it by IsConverged and Err
+ */
+type LPLearner struct {
+	*scorer.Vectoriser
+	MaxWeight     float64
+	MaxIterations int
+	Tolerance     float64
+	OnEvent       spell.LearnEventHandler
+	report        LPLearnReport
+}
+
+func InitLPLearner(vectoriser *scorer.Vectoriser) *LPLearner {
+	return &LPLearner{
+		Vectoriser:    vectoriser,
+		MaxWeight:     100,
+		MaxIterations: 1000,
+		Tolerance:     1e-6,
+	}
+}
+
+func (learner *LPLearner) Learn(learningData []*spell.LearningTerm) spell.ScoreModel {
+	learner.report = LPLearnReport{}
+	startedAt := time.Now()
+	var (
+		vectorSystems = make([]*VectorSystem, 0, len(learningData))
+		systemsTerms  = make([]*spell.LearningTerm, 0, len(learningData))
+	)
+	for _, learningTerm := range learningData {
+		if vectorSystem := learningVectorSystem(learner.Vectoriser, learningTerm); vectorSystem != nil {
+			vectorSystems = append(vectorSystems, vectorSystem)
+			systemsTerms = append(systemsTerms, learningTerm)
+		}
+	}
+	learner.report.VectorSystemsCount = len(vectorSystems)
+	if len(vectorSystems) == 0 {
+		weights := scorer.InitVector(len(learner.SlotNames()))
+		learner.notify(startedAt, weights, true)
+		return &Scorer{Weights: weights, FeatureNames: learner.SlotNames(), Metadata: scorer.TrainingMetadata{Learner: "lp", FeaturesVersion: scorer.FeaturesVersion}, Vectoriser: learner.Vectoriser}
+	}
+
+	var (
+		dimension   = vectorSystems[0].Vectors[0].Len()
+		weights     = scorer.InitVector(dimension)
+		bestWeights *scorer.Vector
+		bestSlack   = math.Inf(1)
+		cuts        = make([]lpCut, 0, learner.MaxIterations)
+	)
+	for i := range weights.Xs {
+		weights.Xs[i] = 1
+	}
+	for iteration := 1; iteration <= learner.MaxIterations; iteration++ {
+		learner.report.Iterations = iteration
+		slack, subgradient := learner.slack(vectorSystems, weights)
+		if slack < bestSlack {
+			bestSlack = slack
+			bestWeights = weights
+			learner.report.TotalSlack = slack
+			learner.report.SatisfiedCount = satisfiedCount(vectorSystems, weights)
+		}
+		cuts = append(cuts, lpCut{
+			value:       slack - subgradient.ScalarMul(weights),
+			subgradient: subgradient,
+		})
+
+		nextWeights, lowerBound, err := learner.solveMaster(cuts, dimension)
+		if err != nil {
+			learner.report.Err = fmt.Errorf("master program of iteration %d: %v", iteration, err)
+			break
+		}
+		learner.report.LowerBound = lowerBound
+		if bestSlack-lowerBound <= learner.Tolerance*(1+bestSlack) {
+			learner.report.IsConverged = true
+			break
+		}
+		learner.notify(startedAt, bestWeights, false)
+		weights = nextWeights
+	}
+
+	for i, vectorSystem := range vectorSystems {
+		if !vectorSystem.IsSatisfied(bestWeights) {
+			learner.report.Conflicts = append(learner.report.Conflicts, systemsTerms[i])
+		}
+	}
+
+	learner.notify(startedAt, bestWeights, true)
+
+	// the scorer is scale invariant, weights are brought to [0, 1] like other learners have
+	maxWeight := 0.0
+	for _, x := range bestWeights.Xs {
+		maxWeight = math.Max(maxWeight, x)
+	}
+	if maxWeight > 0 {
+		for i := range bestWeights.Xs {
+			bestWeights.Xs[i] /= maxWeight
+		}
+	}
+	return &Scorer{
+		Weights:      bestWeights,
+		FeatureNames: learner.SlotNames(),
+		Metadata: scorer.TrainingMetadata{
+			Learner:         "lp",
+			FeaturesVersion: scorer.FeaturesVersion,
+		},
+		Vectoriser: learner.Vectoriser,
+	}
+}
+
+func (learner *LPLearner) Report() LPLearnReport {
+	return learner.report
+}
+
+func (learner *LPLearner) LearnProgress() string {
+	report := learner.report
+	progress := fmt.Sprintf("Iteration %d. Slack: %f. Lower bound: %f. Satisfied: %d of %d. Conflicts: %d",
+		report.Iterations, report.TotalSlack, report.LowerBound, report.SatisfiedCount, report.VectorSystemsCount, len(report.Conflicts))
+	if report.Err != nil {
+		progress += fmt.Sprintf(". Failed: %v", report.Err)
+	}
+	return progress
+}
+
+func (learner *LPLearner) notify(startedAt time.Time, weights *scorer.Vector, isFinal bool) {
+	if learner.OnEvent == nil {
+		return
+	}
+	learner.OnEvent(spell.LearnEvent{
+		Learner:   "lp",
+		Step:      learner.report.Iterations,
+		BestScore: float64(learner.report.SatisfiedCount),
+		Loss:      learner.report.TotalSlack,
+		Elapsed:   time.Since(startedAt),
+		Weights:   append([]float64(nil), weights.Xs...),
+		IsFinal:   isFinal,
+	})
+}
+
+func satisfiedCount(vectorSystems []*VectorSystem, weights *scorer.Vector) int {
+	count := 0
+	for _, vectorSystem := range vectorSystems {
+		if vectorSystem.IsSatisfied(weights) {
+			count++
+		}
+	}
+	return count
+}
+
+// cut t >= value + subgradient·w
+type lpCut struct {
+	value       float64
+	subgradient *scorer.Vector
+}
+
+/**
+	Total slack of the systems and its subgradient
+ */
+func (learner *LPLearner) slack(vectorSystems []*VectorSystem, weights *scorer.Vector) (float64, *scorer.Vector) {
+	var (
+		total       = 0.0
+		subgradient = scorer.InitVector(weights.Len())
+	)
+	for _, vectorSystem := range vectorSystems {
+		var (
+			minMargin     = math.Inf(1)
+			minInequality *scorer.Vector
+		)
+		for _, inequality := range vectorSystem.Vectors {
+			if margin := inequality.ScalarMul(weights); margin < minMargin {
+				minMargin = margin
+				minInequality = inequality
+			}
+		}
+		if minMargin < 1 {
+			total += 1 - minMargin
+			for i, x := range minInequality.Xs {
+				subgradient.Xs[i] -= x
+			}
+		}
+	}
+	return total, subgradient
+}
+
+/**
+	Solve min t subject to t - g·w >= value for every cut, w <= MaxWeight, t, w >= 0.
+	Its dual program max Σ value·y - MaxWeight·Σ z subject to Σ y <= 1, -Σ g·y - z <= 0
+	has the feasible origin, so simplex solves the dual and the master solution is taken from the duals
+ */
+func (learner *LPLearner) solveMaster(cuts []lpCut, dimension int) (*scorer.Vector, float64, error) {
+	var (
+		columns = len(cuts) + dimension
+		c       = make([]float64, columns)
+		a       = make([][]float64, dimension+1)
+		b       = make([]float64, dimension+1)
+	)
+	for j, cut := range cuts {
+		c[j] = cut.value
+	}
+	for i := 0; i < dimension; i++ {
+		c[len(cuts)+i] = -learner.MaxWeight
+	}
+
+	a[0] = make([]float64, columns)
+	for j := range cuts {
+		a[0][j] = 1
+	}
+	b[0] = 1
+	for i := 0; i < dimension; i++ {
+		a[i+1] = make([]float64, columns)
+		for j, cut := range cuts {
+			a[i+1][j] = -cut.subgradient.Xs[i]
+		}
+		a[i+1][len(cuts)+i] = -1
+	}
+
+	_, duals, objective, err := solveSimplex(c, a, b)
+	if err != nil {
+		return nil, 0, err
+	}
+	weights := scorer.InitVector(dimension)
+	copy(weights.Xs, duals[1:])
+	return weights, objective, nil
+}
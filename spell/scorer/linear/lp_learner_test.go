@@ -0,0 +1,43 @@
+package linear
+
+import (
+	"math"
+	"spell"
+	"spell/scorer"
+	"testing"
+)
+
+func learningTerm(measurer *spell.DistanceMeasurer, term, misspell string, wrongTerms ...string) *spell.LearningTerm {
+	learningTerm := &spell.LearningTerm{Term: term, Misspell: misspell}
+	for _, suggestionTerm := range append([]string{term}, wrongTerms...) {
+		distance, prescription := measurer.Distance(suggestionTerm, misspell, true)
+		learningTerm.Suggestions = append(learningTerm.Suggestions, spell.Suggestion{
+			Term:         suggestionTerm,
+			Distance:     distance,
+			Prescription: prescription,
+		})
+	}
+	return learningTerm
+}
+
+func TestLPLearnerConvergesOnSeparableSystems(t *testing.T) {
+	measurer := spell.NewDistanceMeasurer()
+	learningData := []*spell.LearningTerm{
+		learningTerm(measurer, "text", "tect", "tent"),
+		learningTerm(measurer, "cat", "cst", "cut"),
+		learningTerm(measurer, "hello", "hrllo", "hallo"),
+	}
+	learner := InitLPLearner(scorer.InitVectoriser())
+	learner.Learn(learningData)
+
+	report := learner.Report()
+	if report.Err != nil || !report.IsConverged {
+		t.Fatalf("learning isn't converged: %v", report.Err)
+	}
+	if math.Abs(report.TotalSlack-report.LowerBound) > 1e-6 || report.TotalSlack > 1e-6 {
+		t.Errorf("slack is %v and lower bound is %v, expected both 0", report.TotalSlack, report.LowerBound)
+	}
+	if report.SatisfiedCount != report.VectorSystemsCount || report.VectorSystemsCount != len(learningData) {
+		t.Errorf("%d of %d systems are satisfied, expected all %d", report.SatisfiedCount, report.VectorSystemsCount, len(learningData))
+	}
+}
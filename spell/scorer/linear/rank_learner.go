@@ -0,0 +1,155 @@
+package linear
+
+import (
+	"fmt"
+	"math"
+	"spell"
+	"spell/scorer"
+	"time"
+)
+
+/**
+	PairwiseFit trains the logistic pairwise ranking model (RankNet) by full batch gradient descent.
+	Every difference d = x(wrong) - x(correct) should get w·d > 0, the mean of log(1 + exp(-w·d))
+	plus L2/2·|w|² is minimized
+ */
+type PairwiseFit struct {
+	LearningRate float64
+	L2           float64
+	Epochs       int
+}
+
+/**
+	Fit weights starting from zeros, onEpoch (if any) gets the loss and the weights after every epoch.
+	Nil is returned when there are no differences
+ */
+func (fit *PairwiseFit) Fit(differences []*scorer.Vector, onEpoch func(epoch int, loss float64, weights *scorer.Vector)) *scorer.Vector {
+	if len(differences) == 0 {
+		return nil
+	}
+	var (
+		weights  = scorer.InitVector(differences[0].Len())
+		gradient = scorer.InitVector(differences[0].Len())
+		count    = float64(len(differences))
+	)
+	for epoch := 1; epoch <= fit.Epochs; epoch++ {
+		for i := range gradient.Xs {
+			gradient.Xs[i] = fit.L2 * weights.Xs[i]
+		}
+		for _, difference := range differences {
+			margin := difference.ScalarMul(weights)
+			// d/dw log(1 + exp(-w·d)) = -σ(-w·d)·d
+			factor := -sigmoid(-margin) / count
+			for i, x := range difference.Xs {
+				gradient.Xs[i] += factor * x
+			}
+		}
+		for i := range weights.Xs {
+			weights.Xs[i] -= fit.LearningRate * gradient.Xs[i]
+		}
+		if onEpoch != nil {
+			onEpoch(epoch, fit.Loss(differences, weights), weights)
+		}
+	}
+	return weights
+}
+
+func (fit *PairwiseFit) Loss(differences []*scorer.Vector, weights *scorer.Vector) float64 {
+	loss := 0.0
+	for _, difference := range differences {
+		loss += logLoss(difference.ScalarMul(weights))
+	}
+	loss /= float64(len(differences))
+	return loss + fit.L2/2*weights.ScalarMul(weights)
+}
+
+func sigmoid(x float64) float64 {
+	return 1 / (1 + math.Exp(-x))
+}
+
+// log(1 + exp(-x)) without overflows
+func logLoss(x float64) float64 {
+	if x > 0 {
+		return math.Log1p(math.Exp(-x))
+	}
+	return -x + math.Log1p(math.Exp(x))
+}
+
+type RankLearnProgress struct {
+	PairsCount int
+	Epoch      int
+	Loss       float64
+}
+
+/**
+	RankLearner fits linear Scorer weights with PairwiseFit on the same difference vectors Learner uses.
+	It has no randomness, the same data always give the same weights
+ */
+type RankLearner struct {
+	*scorer.Vectoriser
+	PairwiseFit
+	OnEvent       spell.LearnEventHandler
+	learnProgress RankLearnProgress
+}
+
+func InitRankLearner(vectoriser *scorer.Vectoriser) *RankLearner {
+	return &RankLearner{
+		Vectoriser: vectoriser,
+		PairwiseFit: PairwiseFit{
+			LearningRate: 0.5,
+			L2:           0.001,
+			Epochs:       300,
+		},
+	}
+}
+
+func (learner *RankLearner) Learn(learningData []*spell.LearningTerm) spell.ScoreModel {
+	learner.learnProgress = RankLearnProgress{}
+	startedAt := time.Now()
+	differences := make([]*scorer.Vector, 0, len(learningData))
+	for _, vectorSystem := range learningVectorSystems(learner.Vectoriser, learningData) {
+		differences = append(differences, vectorSystem.Vectors...)
+	}
+	learner.learnProgress.PairsCount = len(differences)
+
+	weights := learner.Fit(differences, func(epoch int, loss float64, weights *scorer.Vector) {
+		learner.learnProgress.Epoch = epoch
+		learner.learnProgress.Loss = loss
+		if learner.OnEvent != nil {
+			learner.OnEvent(spell.LearnEvent{
+				Learner: "ranknet",
+				Step:    epoch,
+				Loss:    loss,
+				Elapsed: time.Since(startedAt),
+				Weights: append([]float64(nil), weights.Xs...),
+			})
+		}
+	})
+	if weights == nil {
+		weights = scorer.InitVector(len(learner.SlotNames()))
+	}
+	if learner.OnEvent != nil {
+		learner.OnEvent(spell.LearnEvent{
+			Learner: "ranknet",
+			Step:    learner.learnProgress.Epoch,
+			Loss:    learner.learnProgress.Loss,
+			Elapsed: time.Since(startedAt),
+			Weights: append([]float64(nil), weights.Xs...),
+			IsFinal: true,
+		})
+	}
+	return &Scorer{
+		Weights:      weights,
+		FeatureNames: learner.SlotNames(),
+		Metadata: scorer.TrainingMetadata{
+			Learner:         "ranknet",
+			FeaturesVersion: scorer.FeaturesVersion,
+		},
+		Vectoriser: learner.Vectoriser,
+	}
+}
+
+func (learner *RankLearner) LearnProgress() string {
+	learnProgress := learner.learnProgress
+	return fmt.Sprintf("Epoch %d. Loss: %f. Pairs count: %d", learnProgress.Epoch, learnProgress.Loss, learnProgress.PairsCount)
+}
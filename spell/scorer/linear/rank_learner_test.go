@@ -0,0 +1,25 @@
+package linear
+
+import (
+	"spell"
+	"spell/scorer"
+	"testing"
+)
+
+func TestRankLearnerWithoutDataEndsWithFinalEvent(t *testing.T) {
+	learner := InitRankLearner(scorer.InitVectoriser())
+	learner.Epochs = 0
+	finalEvents := 0
+	learner.OnEvent = func(event spell.LearnEvent) {
+		if event.IsFinal {
+			finalEvents++
+		}
+	}
+	linearScorer := learner.Learn(nil).(*Scorer)
+	if finalEvents != 1 {
+		t.Errorf("%d final events are sent, expected 1", finalEvents)
+	}
+	if linearScorer.Weights == nil || linearScorer.Weights.Len() != len(learner.SlotNames()) {
+		t.Errorf("weights %v don't have a slot per feature", linearScorer.Weights)
+	}
+}
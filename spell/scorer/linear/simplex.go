@@ -0,0 +1,113 @@
+package linear
+
+import (
+	"errors"
+	"math"
+)
+
+var (
+	errUnboundedProgram = errors.New("linear program is unbounded")
+	errSimplexLimit     = errors.New("simplex iterations limit is reached")
+)
+
+const simplexEps = 1e-9
+
+/**
+	Solve max c·y subject to A·y <= b, y >= 0 where b >= 0 by the tableau simplex method with Bland's rule.
+	Besides the solution duals of the constraints are returned, they solve the dual program
+	min b·x subject to Aᵀ·x >= c, x >= 0.
+	Bland's rule doesn't cycle in exact arithmetic only, so tolerances grow with the largest coefficient:
+	rounding errors of large coefficients would otherwise pass for negative reduced costs and pivots forever
+ */
+func solveSimplex(c []float64, a [][]float64, b []float64) (y []float64, duals []float64, objective float64, err error) {
+	var (
+		rows    = len(a)
+		columns = len(c)
+		width   = columns + rows + 1
+		tableau = make([][]float64, rows+1)
+		basis   = make([]int, rows)
+	)
+	for i := 0; i < rows; i++ {
+		tableau[i] = make([]float64, width)
+		copy(tableau[i], a[i])
+		tableau[i][columns+i] = 1
+		tableau[i][width-1] = b[i]
+		basis[i] = columns + i
+	}
+	objectiveRow := make([]float64, width)
+	for j := 0; j < columns; j++ {
+		objectiveRow[j] = -c[j]
+	}
+	tableau[rows] = objectiveRow
+
+	scale := 1.0
+	for i := range a {
+		for _, x := range a[i] {
+			scale = math.Max(scale, math.Abs(x))
+		}
+		scale = math.Max(scale, b[i])
+	}
+	for _, x := range c {
+		scale = math.Max(scale, math.Abs(x))
+	}
+	eps := simplexEps * scale
+
+	maxIterations := 50 * (rows + columns + 1)
+	for iteration := 0; ; iteration++ {
+		if iteration == maxIterations {
+			return nil, nil, 0, errSimplexLimit
+		}
+		entering := -1
+		for j := 0; j < width-1; j++ {
+			if objectiveRow[j] < -eps {
+				entering = j
+				break
+			}
+		}
+		if entering < 0 {
+			break
+		}
+
+		leaving := -1
+		minRatio := math.Inf(1)
+		for i := 0; i < rows; i++ {
+			if tableau[i][entering] > eps {
+				ratio := tableau[i][width-1] / tableau[i][entering]
+				if ratio < minRatio-eps || (math.Abs(ratio-minRatio) <= eps && basis[i] < basis[leaving]) {
+					minRatio = ratio
+					leaving = i
+				}
+			}
+		}
+		if leaving < 0 {
+			return nil, nil, 0, errUnboundedProgram
+		}
+
+		pivot := tableau[leaving][entering]
+		for j := range tableau[leaving] {
+			tableau[leaving][j] /= pivot
+		}
+		for i := 0; i <= rows; i++ {
+			if i == leaving || tableau[i][entering] == 0 {
+				continue
+			}
+			factor := tableau[i][entering]
+			for j := range tableau[i] {
+				tableau[i][j] -= factor * tableau[leaving][j]
+			}
+		}
+		basis[leaving] = entering
+	}
+
+	y = make([]float64, columns)
+	for i, j := range basis {
+		if j < columns {
+			y[j] = tableau[i][width-1]
+		}
+	}
+	duals = make([]float64, rows)
+	for i := 0; i < rows; i++ {
+		duals[i] = objectiveRow[columns+i]
+	}
+	return y, duals, objectiveRow[width-1], nil
+}
@@ -0,0 +1,73 @@
+package linear
+
+import (
+	"math"
+	"testing"
+)
+
+func TestSolveSimplex(t *testing.T) {
+	cases := []struct {
+		name      string
+		c         []float64
+		a         [][]float64
+		b         []float64
+		objective float64
+		y         []float64
+	}{
+		{
+			name:      "textbook",
+			c:         []float64{3, 5},
+			a:         [][]float64{{1, 0}, {0, 2}, {3, 2}},
+			b:         []float64{4, 12, 18},
+			objective: 36,
+			y:         []float64{2, 6},
+		},
+		{
+			name:      "textbook scaled",
+			c:         []float64{3e4, 5e4},
+			a:         [][]float64{{1e4, 0}, {0, 2e4}, {3e4, 2e4}},
+			b:         []float64{4e4, 12e4, 18e4},
+			objective: 36e4,
+			y:         []float64{2, 6},
+		},
+		{
+			// cycles with the largest coefficient rule
+			name:      "beale",
+			c:         []float64{0.75, -20, 0.5, -6},
+			a:         [][]float64{{0.25, -8, -1, 9}, {0.5, -12, -0.5, 3}, {0, 0, 1, 0}},
+			b:         []float64{0, 0, 1},
+			objective: 1.25,
+			y:         []float64{1, 0, 1, 0},
+		},
+	}
+	for _, c := range cases {
+		y, duals, objective, err := solveSimplex(c.c, c.a, c.b)
+		if err != nil {
+			t.Errorf("%s: %v", c.name, err)
+			continue
+		}
+		if math.Abs(objective-c.objective) > 1e-6*math.Abs(c.objective) {
+			t.Errorf("%s: objective is %v, expected %v", c.name, objective, c.objective)
+		}
+		for j := range c.y {
+			if math.Abs(y[j]-c.y[j]) > 1e-6 {
+				t.Errorf("%s: solution is %v, expected %v", c.name, y, c.y)
+				break
+			}
+		}
+		// strong duality
+		dualObjective := 0.0
+		for i := range duals {
+			dualObjective += c.b[i] * duals[i]
+		}
+		if math.Abs(dualObjective-objective) > 1e-6*math.Abs(objective) {
+			t.Errorf("%s: dual objective is %v, primal one is %v", c.name, dualObjective, objective)
+		}
+	}
+}
+
+func TestSolveSimplexUnbounded(t *testing.T) {
+	if _, _, _, err := solveSimplex([]float64{1, 0}, [][]float64{{-1, 1}}, []float64{1}); err != errUnboundedProgram {
+		t.Errorf("error is %v, expected %v", err, errUnboundedProgram)
+	}
+}
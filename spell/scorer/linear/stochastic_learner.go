@@ -2,8 +2,12 @@ package linear
 
 import (
 	"fmt"
+	"math/rand"
+	"runtime"
 	"spell"
 	"spell/scorer"
+	"sync"
+	"time"
 )
 
 type vectorScore struct {
@@ -19,90 +23,177 @@ type LearnProgress struct {
 	RelaxingCount      int
 }
 
+/**
+	Learner searches weights satisfying the most vector systems by random restarts of a hill climb.
+	Restarts run in parallel batches of Workers and hand candidates of every climb step to a pool of Workers
+	goroutines, so vectors are scored on all the cores and no more than Workers are scored at once.
+	Seeds of the restarts are drawn from Source up front, restart i has its own random source of the i-th seed
+	and batches are merged in order, so the result depends on the source only.
+	The source seeded by Seed is used if Source isn't set, Seed is recorded in the scorer metadata
+ */
 type Learner struct {
 	*scorer.Vectoriser
+	Seed          int64
+	Source        *rand.Rand // shared with other consumers of the run seed, e.g. the data splitter
+	Workers       int // GOMAXPROCS if not set
+	OnEvent       spell.LearnEventHandler
 	learnProgress LearnProgress
 }
 
+type searchResult struct {
+	vector *scorer.Vector
+	score  int
+}
+
 func (learner *Learner) Learn(learningData []*spell.LearningTerm) spell.ScoreModel {
 	learner.learnProgress = LearnProgress{}
-	vectorSystems := make([]*VectorSystem, 0, len(learningData))
-	for _, learningTerm := range learningData {
-		vectorSystem := learner.vectorSystem(learningTerm)
-		if vectorSystem != nil {
-			vectorSystems = append(vectorSystems, vectorSystem)
-		}
-	}
+	startedAt := time.Now()
+	vectorSystems := learningVectorSystems(learner.Vectoriser, learningData)
 	learner.learnProgress.VectorSystemsCount = len(vectorSystems)
 
 	var bestVector *scorer.Vector
 	if len(vectorSystems) > 0 && vectorSystems[0] != nil &&
 		len(vectorSystems[0].Vectors) > 0 && vectorSystems[0].Vectors[0] != nil {
 		var (
-			vector               *scorer.Vector
-			tries                = 100
-			maxRelaxCount        = 10
-			relaxingCount        = 0
-			maxDirectSearchCount = 1000
-			bestScore            = 0
-			prevBestScore        = 0
+			tries         = 100
+			maxRelaxCount = 10
+			relaxingCount = 0
+			bestScore     = 0
+			prevBestScore = 0
+			workers       = learner.workers()
+			dimension     = vectorSystems[0].Vectors[0].Len()
+			pool          = newScoringPool(workers)
+			restartSeeds  = make([]int64, tries)
+			source        = learner.Source
 		)
+		defer pool.close()
+		if source == nil {
+			source = rand.New(rand.NewSource(learner.Seed))
+		}
+		for i := range restartSeeds {
+			restartSeeds[i] = source.Int63()
+		}
 		triesLoop:
-		for i := 0; i < tries; i++ {
-			learner.learnProgress.Step = i + 1
-			vector = scorer.RandomVector(vectorSystems[0].Vectors[0].Len())
-			currentScore := learner.score(vectorSystems, vector)
-			for k := 0; k < maxDirectSearchCount; k++ {
-				vVectors := vector.Variate(0, 1, 0.1)
-				valuableVectors := make([]vectorScore, 0, len(vVectors))
-				for _, v := range vVectors {
-					vScore := learner.score(vectorSystems, v)
-					if vScore > currentScore {
-						valuableVectors = append(valuableVectors, vectorScore{
-							Vector: v,
-							score:  float64(vScore),
-						})
-					}
-				}
-				if len(valuableVectors) > 0 {
-					maxScore := 0.0
-					for _, vs := range valuableVectors {
-						if vs.score > maxScore {
-							maxScore = vs.score
-						}
-					}
-					nextVector := vector.Clone()
-					for _, vs := range valuableVectors {
-						nextVector = nextVector.MoveToward(vs.Vector, vs.score / maxScore)
-					}
-					currentScore = learner.score(vectorSystems, nextVector)
-					vector = nextVector
-				} else {
-					break
-				}
+		for batchStart := 0; batchStart < tries; batchStart += workers {
+			batchEnd := batchStart + workers
+			if batchEnd > tries {
+				batchEnd = tries
+			}
+			results := make([]searchResult, batchEnd-batchStart)
+			wg := sync.WaitGroup{}
+			for i := batchStart; i < batchEnd; i++ {
+				wg.Add(1)
+				go func(i int) {
+					defer wg.Done()
+					restartSource := rand.New(rand.NewSource(restartSeeds[i]))
+					results[i-batchStart] = learner.search(pool, vectorSystems, scorer.RandomVector(dimension, restartSource))
+				}(i)
+			}
+			wg.Wait()
 
-				if currentScore > bestScore {
-					bestScore = currentScore
-					bestVector = vector
+			for k, result := range results {
+				learner.learnProgress.Step = batchStart + k + 1
+				if result.score > bestScore {
+					bestScore = result.score
+					bestVector = result.vector
 					learner.learnProgress.BestScore = bestScore
 				}
-			}
-			if bestScore > prevBestScore {
-				relaxingCount = 0
-			} else {
-				relaxingCount++
-				learner.learnProgress.RelaxingCount = relaxingCount
+				if bestScore > prevBestScore {
+					relaxingCount = 0
+				} else {
+					relaxingCount++
+					learner.learnProgress.RelaxingCount = relaxingCount
+				}
+				learner.notify(startedAt, bestVector, false)
 				if relaxingCount == maxRelaxCount {
 					break triesLoop
 				}
+				prevBestScore = bestScore
 			}
-			prevBestScore = bestScore
 		}
 	}
+	if bestVector == nil {
+		bestVector = scorer.InitVector(len(learner.SlotNames()))
+	}
+	learner.notify(startedAt, bestVector, true)
 	return &Scorer{
-		Weights:    bestVector,
-		Vectoriser: scorer.InitVectoriser(),
+		Weights:      bestVector,
+		FeatureNames: learner.SlotNames(),
+		Metadata: scorer.TrainingMetadata{
+			Learner:         "stochastic",
+			Seed:            learner.Seed,
+			FeaturesVersion: scorer.FeaturesVersion,
+		},
+		Vectoriser: learner.Vectoriser,
+	}
+}
+
+/**
+	Hill climb from the vector, the best vector met after the first step is returned
+ */
+func (learner *Learner) search(pool *scoringPool, vectorSystems []*VectorSystem, vector *scorer.Vector) (best searchResult) {
+	maxDirectSearchCount := 1000
+	currentScore := learner.score(vectorSystems, vector)
+	for k := 0; k < maxDirectSearchCount; k++ {
+		vVectors := vector.Variate(0, 1, 0.1)
+		vScores := learner.scoreAll(pool, vectorSystems, vVectors)
+		valuableVectors := make([]vectorScore, 0, len(vVectors))
+		for i, v := range vVectors {
+			if vScores[i] > currentScore {
+				valuableVectors = append(valuableVectors, vectorScore{
+					Vector: v,
+					score:  float64(vScores[i]),
+				})
+			}
+		}
+		if len(valuableVectors) > 0 {
+			maxScore := 0.0
+			for _, vs := range valuableVectors {
+				if vs.score > maxScore {
+					maxScore = vs.score
+				}
+			}
+			nextVector := vector.Clone()
+			for _, vs := range valuableVectors {
+				nextVector = nextVector.MoveToward(vs.Vector, vs.score / maxScore)
+			}
+			currentScore = learner.score(vectorSystems, nextVector)
+			vector = nextVector
+		} else {
+			break
+		}
+
+		if currentScore > best.score {
+			best.score = currentScore
+			best.vector = vector
+		}
 	}
+	return
+}
+
+func (learner *Learner) notify(startedAt time.Time, bestVector *scorer.Vector, isFinal bool) {
+	if learner.OnEvent == nil {
+		return
+	}
+	event := spell.LearnEvent{
+		Learner:       "stochastic",
+		Step:          learner.learnProgress.Step,
+		BestScore:     float64(learner.learnProgress.BestScore),
+		RelaxingCount: learner.learnProgress.RelaxingCount,
+		Elapsed:       time.Since(startedAt),
+		IsFinal:       isFinal,
+	}
+	if bestVector != nil {
+		event.Weights = append([]float64(nil), bestVector.Xs...)
+	}
+	learner.OnEvent(event)
+}
+
+func (learner *Learner) workers() int {
+	if learner.Workers > 0 {
+		return learner.Workers
+	}
+	return runtime.GOMAXPROCS(-1)
 }
 
 func (learner *Learner) LearnProgress() string {
@@ -111,6 +202,49 @@ func (learner *Learner) LearnProgress() string {
 }
 
 
+/**
+	Score vectors in parallel on the pool
+ */
+func (learner *Learner) scoreAll(pool *scoringPool, vectorSystems []*VectorSystem, vectors []*scorer.Vector) []int {
+	scores := make([]int, len(vectors))
+	wg := sync.WaitGroup{}
+	for i, vector := range vectors {
+		wg.Add(1)
+		i, vector := i, vector
+		pool.jobs <- func() {
+			defer wg.Done()
+			scores[i] = learner.score(vectorSystems, vector)
+		}
+	}
+	wg.Wait()
+	return scores
+}
+
+/**
+	Workers goroutines running the scoring jobs of all the restarts
+ */
+type scoringPool struct {
+	jobs chan func()
+}
+
+func newScoringPool(workers int) *scoringPool {
+	pool := &scoringPool{
+		jobs: make(chan func()),
+	}
+	for i := 0; i < workers; i++ {
+		go func() {
+			for job := range pool.jobs {
+				job()
+			}
+		}()
+	}
+	return pool
+}
+
+func (pool *scoringPool) close() {
+	close(pool.jobs)
+}
+
 func (learner *Learner) score(vectorSystems []*VectorSystem, vector *scorer.Vector) int {
 	currentScore := 0
 	for _, vectorSystem := range vectorSystems {
@@ -122,11 +256,25 @@ func (learner *Learner) score(vectorSystems []*VectorSystem, vector *scorer.Vect
 }
 
 
-func (learner *Learner) vectorSystem(a *spell.LearningTerm) *VectorSystem {
+/**
+	Build vector systems of the learning terms, terms without a correct suggestion or wrong ones are skipped
+ */
+func learningVectorSystems(vectoriser *scorer.Vectoriser, learningData []*spell.LearningTerm) []*VectorSystem {
+	vectorSystems := make([]*VectorSystem, 0, len(learningData))
+	for _, learningTerm := range learningData {
+		vectorSystem := learningVectorSystem(vectoriser, learningTerm)
+		if vectorSystem != nil {
+			vectorSystems = append(vectorSystems, vectorSystem)
+		}
+	}
+	return vectorSystems
+}
+
+func learningVectorSystem(vectoriser *scorer.Vectoriser, a *spell.LearningTerm) *VectorSystem {
 	baseVector := (*scorer.Vector)(nil)
-	for _, suggestion := range a.Suggestions {
-		if suggestion.Term == a.Term {
-			baseVector = learner.Vectorize(suggestion.Prescription)
+	for i := range a.Suggestions {
+		if a.Suggestions[i].Term == a.Term {
+			baseVector = vectoriser.VectorizeSuggestion(&a.Suggestions[i])
 			break
 		}
 	}
@@ -135,9 +283,10 @@ func (learner *Learner) vectorSystem(a *spell.LearningTerm) *VectorSystem {
 	}
 
 	vectorSystem := InitVectorSystem()
-	for _, suggestion := range a.Suggestions {
+	for i := range a.Suggestions {
+		suggestion := &a.Suggestions[i]
 		if suggestion.Term != a.Term && a.Misspell != suggestion.Term {
-			vector := learner.Vectorize(suggestion.Prescription)
+			vector := vectoriser.VectorizeSuggestion(suggestion)
 			vector = vector.Sub(baseVector)
 			vectorSystem.Add(vector)
 		}
@@ -147,4 +296,4 @@ func (learner *Learner) vectorSystem(a *spell.LearningTerm) *VectorSystem {
 		return vectorSystem
 	}
 	return nil
-}
\ No newline at end of file
+}
@@ -0,0 +1,26 @@
+package linear
+
+import (
+	"reflect"
+	"spell"
+	"spell/scorer"
+	"testing"
+)
+
+func TestLearnerWeightsDontDependOnWorkers(t *testing.T) {
+	measurer := spell.NewDistanceMeasurer()
+	learningData := []*spell.LearningTerm{
+		learningTerm(measurer, "text", "tect", "tent"),
+		learningTerm(measurer, "cat", "cst", "cut"),
+		learningTerm(measurer, "hello", "hrllo", "hallo"),
+		learningTerm(measurer, "tent", "tetn", "text"),
+	}
+	var weights [][]float64
+	for _, workers := range []int{1, 4} {
+		learner := &Learner{Vectoriser: scorer.InitVectoriser(), Seed: 7, Workers: workers}
+		weights = append(weights, learner.Learn(learningData).(*Scorer).Weights.Xs)
+	}
+	if !reflect.DeepEqual(weights[0], weights[1]) {
+		t.Errorf("weights of 1 worker %v differ from weights of 4 workers %v", weights[0], weights[1])
+	}
+}
@@ -1,14 +1,10 @@
 package linear
 
 import (
-	"fmt"
-	"math"
+	"io"
 	"spell/scorer"
-	"strings"
 )
 
-var eps = 0.000001
-
 type VectorSystem struct {
 	Vectors []*scorer.Vector
 }
@@ -43,28 +39,16 @@ func (system *VectorSystem) Normalize() {
 	system.Vectors = inequalities
 }
 
-func (system *VectorSystem) Dump() {
+/**
+	Write every inequality of the system on its own line
+ */
+func (system *VectorSystem) Dump(w io.Writer) error {
 	for _, inequality := range system.Vectors {
-		displayValues := make([]string, 0, 20)
-		for i, val := range inequality.Xs {
-			if math.Abs(val) > eps {
-				valStr := fmt.Sprintf("%0.1f*x%d", math.Abs(val), i)
-				if len(displayValues) > 0 || val < 0 {
-					sign := "+"
-					if val < 0 {
-						sign = "-"
-					}
-					displayValues = append(displayValues, sign)
-				}
-				displayValues = append(displayValues, valStr)
-			}
-		}
-		if len(displayValues) > 0 {
-			displayValues = append(displayValues, "> 0")
-			displayStr := strings.Join(displayValues, " ")
-			fmt.Printf("%s\n", displayStr)
+		if err := inequality.Dump(w); err != nil {
+			return err
 		}
 	}
+	return nil
 }
 
 func (system *VectorSystem) IsSatisfied(vector *scorer.Vector) bool {
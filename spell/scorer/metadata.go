@@ -0,0 +1,10 @@
+package scorer
+
+/**
+	TrainingMetadata describes how a scorer was trained, the same learner, seed and data give the same scorer
+ */
+type TrainingMetadata struct {
+	Learner         string `json:"learner"`
+	Seed            int64  `json:"seed"`
+	FeaturesVersion int    `json:"features_version"` // FeaturesVersion of the learning, 0 for scorers not using features
+}
@@ -0,0 +1,81 @@
+package scorer
+
+import (
+	"math"
+	"unicode/utf8"
+)
+
+/**
+	Features of the term prior and of the shape of the input, they let learners trade
+	how common a term is against how likely the edits are
+ */
+var PriorFeatures = []string{
+	"log-rarity",
+	"frequency-rank",
+	"term-length",
+	"input-length",
+	"edit-position",
+}
+
+var ExtendedFeatures = append(append([]string{}, EditFeatures...), PriorFeatures...)
+
+func init() {
+	mustRegisterFeature(&Feature{Name: "log-rarity", Extract: logRarity})
+	mustRegisterFeature(&Feature{Name: "frequency-rank", Extract: frequencyRank})
+	mustRegisterFeature(&Feature{Name: "term-length", Extract: termLength})
+	mustRegisterFeature(&Feature{Name: "input-length", Extract: inputLength})
+	mustRegisterFeature(&Feature{Name: "edit-position", Extract: editPosition})
+}
+
+/**
+	-log10 of the term frequency, rarer terms get larger values, 0 if the frequency is unknown
+ */
+func logRarity(vectoriser *Vectoriser, suggestion *Extraction, xs []float64) {
+	if suggestion.Frequency > 0 {
+		xs[0] = -math.Log10(suggestion.Frequency)
+	}
+}
+
+func frequencyRank(vectoriser *Vectoriser, suggestion *Extraction, xs []float64) {
+	xs[0] = suggestion.FrequencyRank
+}
+
+func termLength(vectoriser *Vectoriser, suggestion *Extraction, xs []float64) {
+	xs[0] = float64(utf8.RuneCountInString(suggestion.Term))
+}
+
+/**
+	Input chars are the non zero Tos of the prescription, without a prescription the input is as long as the term
+ */
+func inputLength(vectoriser *Vectoriser, suggestion *Extraction, xs []float64) {
+	if suggestion.Prescription == nil {
+		termLength(vectoriser, suggestion, xs)
+		return
+	}
+	for _, to := range suggestion.Prescription.Tos {
+		if to != 0 {
+			xs[0]++
+		}
+	}
+}
+
+/**
+	Mean relative position of the edits, 0 for the first step and 1 for the last one
+ */
+func editPosition(vectoriser *Vectoriser, suggestion *Extraction, xs []float64) {
+	prescription := suggestion.Prescription
+	if prescription == nil || len(prescription.Actions) < 2 {
+		return
+	}
+	var (
+		sum   = 0.0
+		count = 0
+	)
+	for _, edit := range suggestion.Edits() {
+		sum += float64(edit.Steps[0]) / float64(len(prescription.Actions)-1)
+		count++
+	}
+	if count > 0 {
+		xs[0] = sum / float64(count)
+	}
+}
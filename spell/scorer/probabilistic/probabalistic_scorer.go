@@ -1,6 +1,7 @@
 package probabilistic
 
 import (
+	"io"
 	"math"
 	"spell"
 	"spell/scorer"
@@ -8,11 +9,22 @@ import (
 
 var eps = 0.000001
 
+const ScorerType = "probabilistic"
+
 type Scorer struct {
-	Weights *scorer.Vector
+	Weights      *scorer.Vector
+	FeatureNames []string // slot names of the vectoriser the weights were trained with
+	Metadata     scorer.TrainingMetadata
 	*scorer.Vectoriser
 }
 
+/**
+	Validate fails if the weights don't fit the vectoriser, e.g. after loading a scorer trained with other features
+ */
+func (scoring *Scorer) Validate() error {
+	return scorer.CheckFeatures(scoring.FeatureNames, scoring.Metadata.FeaturesVersion, scoring.Weights, scoring.Vectoriser)
+}
+
 func (scoring *Scorer) Compare(a *spell.Suggestion, b *spell.Suggestion) float64 {
 	largeValue := math.MaxFloat64
 	if a.Prescription == nil {
@@ -22,15 +34,15 @@ func (scoring *Scorer) Compare(a *spell.Suggestion, b *spell.Suggestion) float64
 		return largeValue
 	}
 
-	vectorA := scoring.Vectorize(a.Prescription)
-	vectorB := scoring.Vectorize(b.Prescription)
+	vectorA := scoring.VectorizeSuggestion(a)
+	vectorB := scoring.VectorizeSuggestion(b)
 	a.Score = a.Count * probabilisticMul(vectorA, scoring.Weights)
 	b.Score = b.Count * probabilisticMul(vectorB, scoring.Weights)
 	return b.Score - a.Score
 }
 
 func (scoring *Scorer) Score(a *spell.Suggestion) float64 {
-	vectorA := scoring.Vectorize(a.Prescription)
+	vectorA := scoring.VectorizeSuggestion(a)
 	score := a.Count * probabilisticMul(vectorA, scoring.Weights)
 	return 1 - score
 }
@@ -45,3 +57,69 @@ func probabilisticMul(a, w *scorer.Vector) float64 {
 	return result
 }
 
+// log of a count or a weight, zeros are taken as eps so explanations stay finite
+func smoothedLog(x float64) float64 {
+	return math.Log(math.Max(x, eps))
+}
+
+
+/**
+	Contributions in the log domain: the log count and value·log(weight) of the features with non zero values.
+	They sum up to log(1 - Score), a zero count or weight zeroes the score and is explained as eps instead of -Inf
+ */
+func (scoring *Scorer) ExplainScore(a *spell.Suggestion) []spell.FeatureContribution {
+	var (
+		vector        = scoring.VectorizeSuggestion(a)
+		names         = scoring.SlotNames()
+		contributions = []spell.FeatureContribution{{
+			Name:         "log-count",
+			Value:        smoothedLog(a.Count),
+			Weight:       1,
+			Contribution: smoothedLog(a.Count),
+		}}
+	)
+	for i, value := range vector.Xs {
+		if math.Abs(value) <= eps {
+			continue
+		}
+		logWeight := smoothedLog(scoring.Weights.Xs[i])
+		contributions = append(contributions, spell.FeatureContribution{
+			Name:         names[i],
+			Value:        value,
+			Weight:       logWeight,
+			Contribution: value * logWeight,
+		})
+	}
+	return contributions
+}
+
+/**
+	Write the scorer as a JSON document, weights are listed with their feature slot names
+ */
+func (scoring *Scorer) WriteJSON(w io.Writer) error {
+	document, err := scorer.NewDocument(ScorerType, scoring.Weights, scoring.FeatureNames, scoring.Metadata, scoring.Vectoriser)
+	if err != nil {
+		return err
+	}
+	return document.Write(w)
+}
+
+/**
+	ReadScorerJSON reads a scorer written by WriteJSON, it fails if the weights don't fit the vectoriser
+ */
+func ReadScorerJSON(r io.Reader, vectoriser *scorer.Vectoriser) (*Scorer, error) {
+	document, err := scorer.ReadDocument(r, ScorerType)
+	if err != nil {
+		return nil, err
+	}
+	weights, slotNames, err := document.CheckedWeights(vectoriser)
+	if err != nil {
+		return nil, err
+	}
+	return &Scorer{
+		Weights:      weights,
+		FeatureNames: slotNames,
+		Metadata:     document.Metadata,
+		Vectoriser:   vectoriser,
+	}, nil
+}
@@ -0,0 +1,48 @@
+package probabilistic
+
+import (
+	"math"
+	"spell"
+	"spell/scorer"
+	"testing"
+)
+
+func TestExplainScore(t *testing.T) {
+	_, prescription := spell.NewDistanceMeasurer().Distance("text", "tect", true)
+	cases := []struct {
+		name          string
+		count         float64
+		replaceWeight float64
+		isZero        bool
+	}{
+		{"positive", 4, 0.5, false},
+		{"zero count", 0, 0.5, true},
+		{"zero weight", 4, 0, true},
+	}
+	for _, c := range cases {
+		vectoriser := scorer.InitVectoriser()
+		weights := scorer.InitVector(vectoriser.Size())
+		for i := range weights.Xs {
+			weights.Xs[i] = 0.5
+		}
+		weights.Xs[scorer.RDistance1] = c.replaceWeight
+		scoring := &Scorer{Weights: weights, Vectoriser: vectoriser}
+		suggestion := &spell.Suggestion{Term: "text", Count: c.count, Prescription: prescription}
+
+		sum := 0.0
+		for _, contribution := range scoring.ExplainScore(suggestion) {
+			sum += contribution.Contribution
+		}
+		score := scoring.Score(suggestion)
+		if math.IsInf(sum, 0) || math.IsNaN(sum) {
+			t.Errorf("%s: contributions sum up to %v", c.name, sum)
+		}
+		// explanations don't change scores, a zero count or weight still zeroes the product
+		if c.isZero && score != 1 {
+			t.Errorf("%s: score is %v, expected 1", c.name, score)
+		}
+		if !c.isZero && math.Abs(sum-math.Log(1-score)) > 1e-9 {
+			t.Errorf("%s: contributions sum up to %v, expected %v", c.name, sum, math.Log(1-score))
+		}
+	}
+}
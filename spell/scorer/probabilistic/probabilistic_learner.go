@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"spell"
 	"spell/scorer"
+	"time"
 )
 
 type LearnProgress struct {
@@ -13,16 +14,18 @@ type LearnProgress struct {
 
 type Learner struct {
 	*scorer.Vectoriser
+	OnEvent       spell.LearnEventHandler
 	learnProgress LearnProgress
 }
 
 func (learner *Learner) Learn(learningData []*spell.LearningTerm) spell.ScoreModel {
 	learner.learnProgress = LearnProgress{}
+	startedAt := time.Now()
 	var weights *scorer.Vector = nil
 	for _, learningTerm := range learningData {
-		for _, suggestion := range learningTerm.Suggestions{
-			if suggestion.Term == learningTerm.Term {
-				vector := learner.Vectorize(suggestion.Prescription)
+		for i := range learningTerm.Suggestions{
+			if learningTerm.Suggestions[i].Term == learningTerm.Term {
+				vector := learner.VectorizeSuggestion(&learningTerm.Suggestions[i])
 				if weights == nil {
 					weights = vector
 				} else {
@@ -39,8 +42,22 @@ func (learner *Learner) Learn(learningData []*spell.LearningTerm) spell.ScoreMod
 		weights.Xs[i] /= totalW
 	}
 	learner.learnProgress.ProcessedTerms = len(learningData)
+	if learner.OnEvent != nil {
+		learner.OnEvent(spell.LearnEvent{
+			Learner: "probabilistic",
+			Step:    len(learningData),
+			Elapsed: time.Since(startedAt),
+			Weights: append([]float64(nil), weights.Xs...),
+			IsFinal: true,
+		})
+	}
 	return &Scorer{
-		Weights:    weights,
+		Weights:      weights,
+		FeatureNames: learner.SlotNames(),
+		Metadata: scorer.TrainingMetadata{
+			Learner:         "probabilistic",
+			FeaturesVersion: scorer.FeaturesVersion,
+		},
 		Vectoriser: learner.Vectoriser,
 	}
 }
@@ -0,0 +1,141 @@
+package substring
+
+import (
+	"fmt"
+	"spell"
+)
+
+type Position uint8
+
+const (
+	Start  Position = 0
+	Middle Position = 1
+	End    Position = 2
+)
+
+func (position Position) String() string {
+	switch position {
+	case Start:
+		return "start"
+	case Middle:
+		return "middle"
+	case End:
+		return "end"
+	}
+	return fmt.Sprintf("Position(%d)", uint8(position))
+}
+
+/**
+	Rule α→β: term substring Alpha is typed as Beta at Position of the term
+ */
+type Rule struct {
+	Alpha          string
+	Beta           string
+	Position       Position
+	Count          float64
+	LogProbability float64
+}
+
+func ruleKey(alpha, beta string, position Position) string {
+	return fmt.Sprintf("%d\x00%s\x00%s", position, alpha, beta)
+}
+
+/**
+	alignment treats every prescription step as a column; spans of columns make rules.
+	A span mustn't cut multi step edits (transpositions and triplets)
+ */
+type alignment struct {
+	prescription  *spell.EditorialPrescription
+	termPositions []int // term chars before the column
+	groupFrom     []int // first column of the edit the column belongs to
+	groupTo       []int // column after the last column of the edit
+	termLen       int
+}
+
+func newAlignment(prescription *spell.EditorialPrescription) *alignment {
+	var (
+		columnsCount = len(prescription.Actions)
+		a            = &alignment{
+			prescription:  prescription,
+			termPositions: make([]int, columnsCount+1),
+			groupFrom:     make([]int, columnsCount),
+			groupTo:       make([]int, columnsCount),
+		}
+	)
+	for i, from := range prescription.Froms {
+		a.termPositions[i+1] = a.termPositions[i]
+		if from != 0 {
+			a.termPositions[i+1]++
+		}
+	}
+	a.termLen = a.termPositions[columnsCount]
+	for _, edit := range prescription.Edits() {
+		for _, step := range edit.Steps {
+			a.groupFrom[step] = edit.Steps[0]
+			a.groupTo[step] = edit.Steps[len(edit.Steps)-1] + 1
+		}
+	}
+	return a
+}
+
+func (a *alignment) columnsCount() int {
+	return len(a.prescription.Actions)
+}
+
+func (a *alignment) isEdit(column int) bool {
+	return a.prescription.Actions[column] != spell.Match
+}
+
+/**
+	Span [from, to) is valid if it contains edits and doesn't cut any of them
+ */
+func (a *alignment) isValidSpan(from, to int) bool {
+	hasEdit := false
+	for column := from; column < to; column++ {
+		if a.groupFrom[column] < from || a.groupTo[column] > to {
+			return false
+		}
+		if a.isEdit(column) {
+			hasEdit = true
+		}
+	}
+	return hasEdit
+}
+
+// number of edits started in the span
+func (a *alignment) editsCount(from, to int) int {
+	count := 0
+	for column := from; column < to; column++ {
+		if a.isEdit(column) && a.groupFrom[column] == column {
+			count++
+		}
+	}
+	return count
+}
+
+func (a *alignment) rule(from, to int) (alpha, beta string, position Position) {
+	var (
+		alphaR = make([]rune, 0, to-from)
+		betaR  = make([]rune, 0, to-from)
+	)
+	for column := from; column < to; column++ {
+		if char := a.prescription.Froms[column]; char != 0 {
+			alphaR = append(alphaR, char)
+		}
+		if char := a.prescription.Tos[column]; char != 0 {
+			betaR = append(betaR, char)
+		}
+	}
+	return string(alphaR), string(betaR), termPosition(a.termPositions[from], a.termPositions[to], a.termLen)
+}
+
+// position of the term substring [from, to)
+func termPosition(from, to, termLen int) Position {
+	switch {
+	case from == 0:
+		return Start
+	case to == termLen:
+		return End
+	}
+	return Middle
+}
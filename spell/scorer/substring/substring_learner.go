@@ -0,0 +1,130 @@
+package substring
+
+import (
+	"fmt"
+	"math"
+	"spell"
+	"time"
+)
+
+type LearnProgress struct {
+	ProcessedTerms int
+	RulesCount     int
+}
+
+/**
+	Learner extracts α→β rules from spans of up to MaxSpan alignment columns around edits,
+	P(α→β) = count(α→β) / count(α) at the same term position
+ */
+type Learner struct {
+	MaxSpan               int
+	MinRuleCount          float64
+	UnknownLogProbability float64
+	CountSmoothing        float64
+	OnEvent               spell.LearnEventHandler
+	learnProgress         LearnProgress
+}
+
+func InitLearner() *Learner {
+	return &Learner{
+		MaxSpan:               4,
+		MinRuleCount:          2,
+		UnknownLogProbability: math.Log(1e-6),
+		CountSmoothing:        1,
+	}
+}
+
+func (learner *Learner) Learn(learningData []*spell.LearningTerm) spell.ScoreModel {
+	learner.learnProgress = LearnProgress{}
+	startedAt := time.Now()
+	var (
+		rules       = map[string]*Rule{}
+		alphaCounts = map[string]float64{}
+		measurer    = spell.NewDistanceMeasurer()
+	)
+	for _, learningTerm := range learningData {
+		prescription := learningPrescription(learningTerm)
+		if prescription == nil {
+			_, prescription = measurer.Distance(learningTerm.Term, learningTerm.Misspell, true)
+		}
+		a := newAlignment(prescription)
+		for to := 1; to <= a.columnsCount(); to++ {
+			for from := to - 1; from >= 0 && from >= to-learner.MaxSpan; from-- {
+				if !a.isValidSpan(from, to) {
+					continue
+				}
+				alpha, beta, position := a.rule(from, to)
+				key := ruleKey(alpha, beta, position)
+				rule, ok := rules[key]
+				if !ok {
+					rule = &Rule{
+						Alpha:    alpha,
+						Beta:     beta,
+						Position: position,
+					}
+					rules[key] = rule
+					alphaCounts[alphaKey(alpha, position)] = 0
+				}
+				rule.Count++
+			}
+		}
+		learner.learnProgress.ProcessedTerms++
+	}
+
+	// occurrences of rules left sides in the correct terms
+	for _, learningTerm := range learningData {
+		termR := []rune(learningTerm.Term)
+		for from := 0; from <= len(termR); from++ {
+			for to := from; to <= len(termR) && to-from <= learner.MaxSpan; to++ {
+				key := alphaKey(string(termR[from:to]), termPosition(from, to, len(termR)))
+				if _, ok := alphaCounts[key]; ok {
+					alphaCounts[key]++
+				}
+			}
+		}
+	}
+
+	for key, rule := range rules {
+		alphaCount := alphaCounts[alphaKey(rule.Alpha, rule.Position)]
+		if rule.Count < learner.MinRuleCount || alphaCount < rule.Count {
+			delete(rules, key)
+			continue
+		}
+		rule.LogProbability = math.Log(rule.Count / alphaCount)
+	}
+	learner.learnProgress.RulesCount = len(rules)
+	if learner.OnEvent != nil {
+		learner.OnEvent(spell.LearnEvent{
+			Learner: "substring",
+			Step:    learner.learnProgress.ProcessedTerms,
+			Elapsed: time.Since(startedAt),
+			IsFinal: true,
+		})
+	}
+
+	return &Scorer{
+		Rules:                 rules,
+		MaxSpan:               learner.MaxSpan,
+		UnknownLogProbability: learner.UnknownLogProbability,
+		CountSmoothing:        learner.CountSmoothing,
+	}
+}
+
+func (learner *Learner) LearnProgress() string {
+	learnProgress := learner.learnProgress
+	return fmt.Sprintf("Processed terms: %d. Rules: %d", learnProgress.ProcessedTerms, learnProgress.RulesCount)
+}
+
+func alphaKey(alpha string, position Position) string {
+	return fmt.Sprintf("%d\x00%s", position, alpha)
+}
+
+// prescription of the correct suggestion
+func learningPrescription(learningTerm *spell.LearningTerm) *spell.EditorialPrescription {
+	for _, suggestion := range learningTerm.Suggestions {
+		if suggestion.Term == learningTerm.Term {
+			return suggestion.Prescription
+		}
+	}
+	return nil
+}
@@ -0,0 +1,140 @@
+package substring
+
+import (
+	"fmt"
+	"math"
+	"spell"
+)
+
+/**
+	Scorer ranks candidates by P(misspell|term)·P(term) where P(misspell|term) is the probability
+	of the best partition of the alignment into rules, as in Brill and Moore.
+	Edits no rule covers get UnknownLogProbability
+ */
+type Scorer struct {
+	Rules                 map[string]*Rule
+	MaxSpan               int
+	UnknownLogProbability float64
+	CountSmoothing        float64 // added to Suggestion.Count
+}
+
+func (scoring *Scorer) Compare(a *spell.Suggestion, b *spell.Suggestion) float64 {
+	a.Score = scoring.Score(a)
+	b.Score = scoring.Score(b)
+	return a.Score - b.Score
+}
+
+/**
+	Negative log probability, the lower the better
+ */
+func (scoring *Scorer) Score(a *spell.Suggestion) float64 {
+	return -(scoring.LogErrorProbability(a.Prescription) + math.Log(a.Count+scoring.CountSmoothing))
+}
+
+/**
+	log P(misspell|term), zero for exact matches
+ */
+func (scoring *Scorer) LogErrorProbability(prescription *spell.EditorialPrescription) float64 {
+	logProbability, _ := scoring.bestPartition(prescription)
+	return logProbability
+}
+
+/**
+	A contribution per rule of the best partition, one per span of edits without rules
+	and one of the term count, they sum up to Score
+ */
+func (scoring *Scorer) ExplainScore(a *spell.Suggestion) []spell.FeatureContribution {
+	logCount := math.Log(a.Count + scoring.CountSmoothing)
+	contributions := []spell.FeatureContribution{{
+		Name:         "log-count",
+		Value:        logCount,
+		Weight:       -1,
+		Contribution: -logCount,
+	}}
+	_, segments := scoring.bestPartition(a.Prescription)
+	for _, segment := range segments {
+		if segment.rule == nil {
+			contributions = append(contributions, spell.FeatureContribution{
+				Name:         "unknown",
+				Value:        float64(segment.editsCount),
+				Weight:       -scoring.UnknownLogProbability,
+				Contribution: -float64(segment.editsCount) * scoring.UnknownLogProbability,
+			})
+			continue
+		}
+		rule := segment.rule
+		contributions = append(contributions, spell.FeatureContribution{
+			Name:         fmt.Sprintf("%s→%s at %s", rule.Alpha, rule.Beta, rule.Position),
+			Value:        1,
+			Weight:       -rule.LogProbability,
+			Contribution: -rule.LogProbability,
+		})
+	}
+	return contributions
+}
+
+/**
+	segment of the best partition, either a rule or edits no rule covers
+ */
+type segment struct {
+	rule       *Rule
+	editsCount int
+}
+
+/**
+	Viterbi over the columns of the alignment, segments are in the column order
+ */
+func (scoring *Scorer) bestPartition(prescription *spell.EditorialPrescription) (float64, []segment) {
+	if prescription == nil {
+		return 0, nil
+	}
+	var (
+		a            = newAlignment(prescription)
+		columnsCount = a.columnsCount()
+		best         = make([]float64, columnsCount+1)
+		bestFrom     = make([]int, columnsCount+1)
+		bestRule     = make([]*Rule, columnsCount+1)
+	)
+	for to := 1; to <= columnsCount; to++ {
+		best[to] = math.Inf(-1)
+		if !a.isEdit(to - 1) {
+			best[to] = best[to-1]
+			bestFrom[to] = to - 1
+		}
+		// edits without rules
+		if from := a.groupFrom[to-1]; a.groupTo[to-1] == to && a.isValidSpan(from, to) {
+			unknown := best[from] + float64(a.editsCount(from, to))*scoring.UnknownLogProbability
+			if unknown > best[to] {
+				best[to] = unknown
+				bestFrom[to] = from
+				bestRule[to] = nil
+			}
+		}
+		for from := to - 1; from >= 0 && from >= to-scoring.MaxSpan; from-- {
+			if !a.isValidSpan(from, to) {
+				continue
+			}
+			if rule, ok := scoring.Rules[ruleKey(a.rule(from, to))]; ok {
+				if logProbability := best[from] + rule.LogProbability; logProbability > best[to] {
+					best[to] = logProbability
+					bestFrom[to] = from
+					bestRule[to] = rule
+				}
+			}
+		}
+	}
+
+	var segments []segment
+	for to := columnsCount; to > 0; to = bestFrom[to] {
+		from := bestFrom[to]
+		if bestRule[to] != nil {
+			segments = append(segments, segment{rule: bestRule[to]})
+		} else if editsCount := a.editsCount(from, to); editsCount > 0 {
+			segments = append(segments, segment{editsCount: editsCount})
+		}
+	}
+	for i, j := 0, len(segments)-1; i < j; i, j = i+1, j-1 {
+		segments[i], segments[j] = segments[j], segments[i]
+	}
+	return best[columnsCount], segments
+}
@@ -0,0 +1,58 @@
+package substring
+
+import (
+	"spell"
+	"testing"
+)
+
+func suggestion(term, input string, count float64) *spell.Suggestion {
+	_, prescription := spell.NewDistanceMeasurer().Distance(term, input, true)
+	return &spell.Suggestion{Term: term, Count: count, Prescription: prescription}
+}
+
+func TestScoreOrder(t *testing.T) {
+	// ph is typed as f at the start
+	var learningData []*spell.LearningTerm
+	for _, pair := range [][2]string{{"phone", "fone"}, {"photo", "foto"}, {"phase", "fase"}} {
+		learningData = append(learningData, &spell.LearningTerm{Term: pair[0], Misspell: pair[1]})
+	}
+	scoring := InitLearner().Learn(learningData).(*Scorer)
+	if len(scoring.Rules) == 0 {
+		t.Fatal("no rules are learned")
+	}
+
+	cases := []struct {
+		name          string
+		better, worse *spell.Suggestion
+	}{
+		{"known rule", suggestion("phase", "fase", 1), suggestion("vase", "fase", 1)},
+		{"frequent term", suggestion("vase", "fase", 10), suggestion("vase", "fase", 1)},
+		{"fewer unknown edits", suggestion("vase", "fase", 1), suggestion("vise", "fake", 1)},
+		{"exact match", suggestion("fase", "fase", 1), suggestion("vase", "fase", 1)},
+	}
+	for _, c := range cases {
+		if scoring.Compare(c.better, c.worse) >= 0 {
+			t.Errorf("%s: %s scores %v, %s scores %v", c.name, c.better.Term, c.better.Score, c.worse.Term, c.worse.Score)
+		}
+	}
+}
+
+func TestExplainScoreSumsUpToScore(t *testing.T) {
+	scoring := InitLearner().Learn([]*spell.LearningTerm{
+		{Term: "phone", Misspell: "fone"},
+		{Term: "photo", Misspell: "foto"},
+	}).(*Scorer)
+	for _, a := range []*spell.Suggestion{suggestion("phase", "fase", 3), suggestion("vase", "fake", 1), suggestion("fase", "fase", 2)} {
+		sum := 0.0
+		for _, contribution := range scoring.ExplainScore(a) {
+			sum += contribution.Contribution
+		}
+		if score := scoring.Score(a); !isClose(sum, score) {
+			t.Errorf("%s: contributions sum up to %v, score is %v", a.Term, sum, score)
+		}
+	}
+}
+
+func isClose(a, b float64) bool {
+	return a-b < 1e-9 && b-a < 1e-9
+}
@@ -2,6 +2,7 @@ package scorer
 
 import (
 	"fmt"
+	"io"
 	"math"
 	"math/rand"
 	"strings"
@@ -19,10 +20,10 @@ func InitVector(length int) *Vector {
 	}
 }
 
-func RandomVector(length int) *Vector {
+func RandomVector(length int, source *rand.Rand) *Vector {
 	result := InitVector(length)
 	for i := range result.Xs {
-		result.Xs[i] = rand.Float64()
+		result.Xs[i] = source.Float64()
 	}
 	return result
 }
@@ -99,7 +100,10 @@ func (a *Vector) MoveToward(vector *Vector, l float64) *Vector{
 	return result
 }
 
-func (inequality *Vector) Dump() {
+/**
+	Write the vector as an inequality, e.g. "0.5*x1 - 1.0*x3 > 0"
+ */
+func (inequality *Vector) Dump(w io.Writer) error {
 	displayValues := make([]string, 0, 20)
 	for i, val := range inequality.Xs {
 		if math.Abs(val) > eps {
@@ -117,8 +121,10 @@ func (inequality *Vector) Dump() {
 	if len(displayValues) > 0 {
 		displayValues = append(displayValues, "> 0")
 		displayStr := strings.Join(displayValues, " ")
-		fmt.Printf("%s\n", displayStr)
+		_, err := fmt.Fprintf(w, "%s\n", displayStr)
+		return err
 	}
+	return nil
 }
 
 func (a *Vector) IsSatisfied(wights *Vector) bool {
@@ -1,8 +1,10 @@
 package scorer
 
 import (
-	"math"
+	"fmt"
 	"spell"
+	"spell/keyboard"
+	"sync"
 )
 
 const (
@@ -24,76 +26,138 @@ const (
 	J              = 15
 )
 
+/**
+	Vectoriser turns suggestions into vectors, a slot per feature slot in the order of FeatureNames.
+	The slot constants above are positions in the EditFeatures set
+ */
 type Vectoriser struct {
+	Layout       *keyboard.Layout
+	FeatureNames []string // EditFeatures if not set
+	features     []*Feature `binary:"-"`
+	resolveOnce  sync.Once  `binary:"-"`
+	resolveErr   error      `binary:"-"`
 }
 
 func InitVectoriser() *Vectoriser  {
-	return new (Vectoriser)
+	return InitVectoriserWithLayout(keyboard.Qwerty)
 }
 
-func (maker *Vectoriser) Vectorize(prescription *spell.EditorialPrescription) *Vector {
-	inequality := InitVector(16)
-	for i := 0; i < len(prescription.Actions); {
-		action := prescription.Actions[i]
-		from := prescription.Froms[i]
-		to := prescription.Tos[i]
-		switch action {
-		case spell.Replace:
-			if i == 0 {
-				inequality.Xs[RFirst] += 1
-			} else {
-				consonantDistance := maker.consonantDistance(from, to)
-				if consonantDistance >= 0 {
-					inequality.Xs[RConsonant] += 1
-				} else {
-					qwertyDistance := maker.qwertyDistance(from, to)
-					if qwertyDistance >= 0 {
-						switch true {
-						case qwertyDistance <= 1+eps:
-							inequality.Xs[RDistance1] += 1
-						case qwertyDistance <= 2+eps:
-							inequality.Xs[RDistance2] += 1
-						case qwertyDistance <= 3+eps:
-							inequality.Xs[RDistance3] += 1
-						case qwertyDistance <= 4+eps:
-							inequality.Xs[RDistance4] += 1
-						default:
-							inequality.Xs[RDistanceOther] += 1
-						}
-					}
-				}
-			}
-		case spell.Delete:
-			switch true {
-			case i == 0:
-				inequality.Xs[DFirst] += 1
-			case i == len(prescription.Actions)-1:
-				inequality.Xs[DLast] += 1
-			default:
-				inequality.Xs[DMiddle] += 1
-			}
-		case spell.Insert:
-			if i == 0 {
-				inequality.Xs[IFirst] += 1
-			} else {
-				inequality.Xs[IMiddle] += 1
+func InitVectoriserWithLayout(layout *keyboard.Layout) *Vectoriser {
+	return &Vectoriser{
+		Layout:       layout,
+		FeatureNames: EditFeatures,
+	}
+}
+
+func InitVectoriserWithFeatures(layout *keyboard.Layout, featureNames []string) (*Vectoriser, error) {
+	vectoriser := &Vectoriser{
+		Layout:       layout,
+		FeatureNames: featureNames,
+	}
+	if err := vectoriser.Validate(); err != nil {
+		return nil, err
+	}
+	return vectoriser, nil
+}
+
+/**
+	Validate checks that all the features are registered
+ */
+func (maker *Vectoriser) Validate() error {
+	_, err := maker.resolve()
+	return err
+}
+
+func (maker *Vectoriser) resolve() ([]*Feature, error) {
+	maker.resolveOnce.Do(func() {
+		featureNames := maker.FeatureNames
+		if featureNames == nil {
+			featureNames = EditFeatures
+		}
+		for _, name := range featureNames {
+			feature, ok := FeatureByName(name)
+			if !ok {
+				maker.resolveErr = fmt.Errorf("unknown feature %q", name)
+				return
 			}
-		case spell.Transposition:
-			inequality.Xs[T] += 1
-			i++
-		case spell.Duplicate:
-			inequality.Xs[P] += 1
-			i++
-		case spell.MissDouble:
-			inequality.Xs[U] += 1
-			i++
-		case spell.Triplet:
-			inequality.Xs[J] += 1
-			i += 2
+			maker.features = append(maker.features, feature)
 		}
-		i++
+	})
+	return maker.features, maker.resolveErr
+}
+
+func (maker *Vectoriser) mustResolve() []*Feature {
+	features, err := maker.resolve()
+	if err != nil {
+		panic(err)
 	}
-	return inequality
+	return features
+}
+
+/**
+	Names of the vector slots
+ */
+func (maker *Vectoriser) SlotNames() []string {
+	var names []string
+	for _, feature := range maker.mustResolve() {
+		names = append(names, feature.SlotNames()...)
+	}
+	return names
+}
+
+func (maker *Vectoriser) Size() int {
+	size := 0
+	for _, feature := range maker.mustResolve() {
+		size += feature.size()
+	}
+	return size
+}
+
+func (maker *Vectoriser) Vectorize(prescription *spell.EditorialPrescription) *Vector {
+	return maker.VectorizeSuggestion(&spell.Suggestion{Prescription: prescription})
+}
+
+func (maker *Vectoriser) VectorizeSuggestion(suggestion *spell.Suggestion) *Vector {
+	features := maker.mustResolve()
+	size := 0
+	for _, feature := range features {
+		size += feature.size()
+	}
+	var (
+		vector     = InitVector(size)
+		extraction = NewExtraction(suggestion)
+		offset     = 0
+	)
+	for _, feature := range features {
+		feature.Extract(maker, extraction, vector.Xs[offset:offset+feature.size()])
+		offset += feature.size()
+	}
+	return vector
+}
+
+/**
+	CheckFeatures fails if weights trained over slotNames don't fit the vectoriser
+ */
+func CheckFeatures(slotNames []string, featuresVersion int, weights *Vector, vectoriser *Vectoriser) error {
+	if err := vectoriser.Validate(); err != nil {
+		return err
+	}
+	if featuresVersion != FeaturesVersion {
+		return fmt.Errorf("scorer was trained with features of version %d but the vectoriser has version %d", featuresVersion, FeaturesVersion)
+	}
+	current := vectoriser.SlotNames()
+	if weights != nil && weights.Len() != len(slotNames) {
+		return fmt.Errorf("scorer has %d weights for %d features", weights.Len(), len(slotNames))
+	}
+	if len(slotNames) != len(current) {
+		return fmt.Errorf("scorer was trained with %d features but the vectoriser has %d", len(slotNames), len(current))
+	}
+	for i := range slotNames {
+		if slotNames[i] != current[i] {
+			return fmt.Errorf("feature %d of the scorer is %q but the vectoriser has %q", i, slotNames[i], current[i])
+		}
+	}
+	return nil
 }
 
 func (maker *Vectoriser) consonantDistance(from, to rune) float64 {
@@ -118,69 +182,10 @@ func (maker *Vectoriser) consonantDistance(from, to rune) float64 {
 	return -1
 }
 
-func (maker *Vectoriser) qwertyDistance(from, to rune) float64 {
-	locationFrom, locationFromExists := distances[from]
-	locationTo, locationToExists := distances[from]
-
-	if locationFromExists && locationToExists {
-		return math.Abs(locationTo.X-locationFrom.X) + math.Abs(locationTo.Y-locationFrom.Y)
+func (maker *Vectoriser) keyDistance(from, to rune) float64 {
+	layout := maker.Layout
+	if layout == nil {
+		layout = keyboard.Qwerty
 	}
-	return -1
-}
-
-type KeyLocation struct {
-	X float64
-	Y float64
-}
-
-var (
-	offset1 = 0.5
-	offset2 = 0.9
-	offset3 = 1.3
-)
-
-var distances = map[rune]KeyLocation{
-	// number row
-	'0': KeyLocation{0, 0},
-	'1': KeyLocation{1, 0},
-	'2': KeyLocation{2, 0},
-	'3': KeyLocation{3, 0},
-	'4': KeyLocation{4, 0},
-	'5': KeyLocation{5, 0},
-	'6': KeyLocation{6, 0},
-	'7': KeyLocation{7, 0},
-	'8': KeyLocation{8, 0},
-	'9': KeyLocation{9, 0},
-
-	// first row
-	'q': KeyLocation{0 + offset1, 1},
-	'w': KeyLocation{1 + offset1, 1},
-	'e': KeyLocation{2 + offset1, 1},
-	'r': KeyLocation{3 + offset1, 1},
-	't': KeyLocation{4 + offset1, 1},
-	'y': KeyLocation{5 + offset1, 1},
-	'u': KeyLocation{6 + offset1, 1},
-	'i': KeyLocation{7 + offset1, 1},
-	'o': KeyLocation{8 + offset1, 1},
-	'p': KeyLocation{9 + offset1, 1},
-
-	// second row
-	'a': KeyLocation{0 + offset2, 2},
-	's': KeyLocation{1 + offset2, 2},
-	'd': KeyLocation{2 + offset2, 2},
-	'f': KeyLocation{3 + offset2, 2},
-	'g': KeyLocation{4 + offset2, 2},
-	'h': KeyLocation{5 + offset2, 2},
-	'j': KeyLocation{6 + offset2, 2},
-	'k': KeyLocation{7 + offset2, 2},
-	'l': KeyLocation{8 + offset2, 2},
-
-	// third row
-	'z': KeyLocation{0 + offset3, 3},
-	'x': KeyLocation{1 + offset3, 3},
-	'c': KeyLocation{2 + offset3, 3},
-	'v': KeyLocation{3 + offset3, 3},
-	'b': KeyLocation{4 + offset3, 3},
-	'n': KeyLocation{5 + offset3, 3},
-	'm': KeyLocation{6 + offset3, 3},
+	return layout.Distance(from, to)
 }
@@ -0,0 +1,41 @@
+package scorer
+
+import (
+	"spell"
+	"spell/keyboard"
+	"testing"
+)
+
+func TestKeyDistanceSlots(t *testing.T) {
+	cases := []struct {
+		term, input string
+		slot        int
+	}{
+		{"text", "tect", RDistance1},
+		{"cat", "cdt", RDistance2},
+	}
+	var (
+		measurer   = spell.NewDistanceMeasurer()
+		vectoriser = InitVectoriser()
+	)
+	for _, c := range cases {
+		_, prescription := measurer.Distance(c.term, c.input, true)
+		if vector := vectoriser.Vectorize(prescription); vector.Xs[c.slot] != 1 {
+			t.Errorf("%s→%s: slot %d is %v in %v", c.term, c.input, c.slot, vector.Xs[c.slot], vector.Xs)
+		}
+	}
+}
+
+func BenchmarkVectorizeSuggestion(b *testing.B) {
+	vectoriser, err := InitVectoriserWithFeatures(keyboard.Qwerty, append(append([]string{}, ExtendedFeatures...), "edit-context"))
+	if err != nil {
+		b.Fatal(err)
+	}
+	_, prescription := spell.NewDistanceMeasurer().Distance("spelling", "speeling", true)
+	suggestion := &spell.Suggestion{Term: "spelling", Prescription: prescription}
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		vectoriser.VectorizeSuggestion(suggestion)
+	}
+}
@@ -36,11 +36,14 @@ type EditorialPrescription struct {
 }
 
 type Suggestion struct {
-	Term         string
-	Distance     int
-	Score        float64
-	Count        float64
-	Prescription *EditorialPrescription
+	Term          string
+	Distance      int
+	Score         float64
+	Count         float64
+	Frequency     float64 // share of the term in the trained text
+	FrequencyRank float64 // 0 for the most frequent of the candidates, 1 for the least frequent one
+	Prescription  *EditorialPrescription
+	IsLayoutFix   bool // input was typed with a wrong keyboard layout
 }
 
 type Misspell struct {
@@ -68,6 +71,33 @@ type ScoreModel interface {
 	Compare(a *Suggestion, b *Suggestion) float64
 }
 
+/**
+	BatchScoreModel scores all the candidates of an input together, for models where the score
+	of a candidate depends on the other candidates, e.g. rank fusion. Scores go to Suggestion.Score
+ */
+type BatchScoreModel interface {
+	ScoreModel
+	ScoreAll(suggestions []Suggestion)
+}
+
+/**
+	FeatureContribution is the part of a score one feature is responsible for, Contribution = Value·Weight
+ */
+type FeatureContribution struct {
+	Name         string
+	Value        float64
+	Weight       float64
+	Contribution float64
+}
+
+/**
+	ScoreExplainer breaks the score of a suggestion down to features, how contributions add up
+	to the score is described by each implementation
+ */
+type ScoreExplainer interface {
+	ExplainScore(a *Suggestion) []FeatureContribution
+}
+
 type Learner interface {
 	Learn(learningData []*LearningTerm) ScoreModel
 }
\ No newline at end of file